@@ -0,0 +1,158 @@
+// Package ai implements an expectimax solver for 2048. It relies on
+// state.Board for all moves, so it plays by exactly the same rules as the
+// player.
+package ai
+
+import (
+	"math"
+	"sort"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// lossPenalty is subtracted from the heuristic value of boards that don't
+// allow any further move.
+const lossPenalty = 1e6
+
+// Solver searches for the best move via expectimax. Player moves maximize
+// the expected value, while spawns are chance nodes that average over all
+// free cells and spawnable values. Leaves are rated by the heuristic.
+type Solver struct {
+	// Depth is the number of player moves to look ahead, including the
+	// move being decided. Depth 1 rates each move by the heuristic value
+	// of the resulting board.
+	Depth int
+	// MinProbability prunes chance nodes that are reached with a lower
+	// probability than this. Pruned nodes are rated by the heuristic.
+	MinProbability float64
+	Heuristic      Heuristic
+	// SpawnPolicy has to match the rules of the game being played.
+	SpawnPolicy state.SpawnPolicy
+}
+
+// NewSolver produces a solver with the given depth, using the default
+// heuristic and spawn policy.
+func NewSolver(depth int) *Solver {
+	return &Solver{
+		Depth:          depth,
+		MinProbability: 0.0001,
+		Heuristic:      DefaultHeuristic(),
+		SpawnPolicy:    state.DefaultSpawnPolicy(),
+	}
+}
+
+// Evaluation is the expected value of a move.
+type Evaluation struct {
+	Direction state.Direction
+	Value     float64
+}
+
+// Evaluate rates all moves that change the board, best move first. If no
+// move is possible, the result is empty.
+func (solver *Solver) Evaluate(board state.Board) []Evaluation {
+	search := &search{solver: solver, cache: make(map[cacheKey]float64)}
+	evaluations := make([]Evaluation, 0, len(state.Directions))
+	for _, direction := range state.Directions {
+		moved, result := board.Move(direction)
+		if !result.Changed {
+			continue
+		}
+		evaluations = append(evaluations, Evaluation{
+			Direction: direction,
+			Value:     search.chance(moved, solver.Depth-1, 1),
+		})
+	}
+
+	//Stable, so that ties are decided by the order of state.Directions.
+	sort.SliceStable(evaluations, func(a, b int) bool {
+		return evaluations[a].Value > evaluations[b].Value
+	})
+	return evaluations
+}
+
+// BestMove returns the move with the highest expected value and that
+// value. If no move is possible, false is returned.
+func (solver *Solver) BestMove(board state.Board) (state.Direction, float64, bool) {
+	evaluations := solver.Evaluate(board)
+	if len(evaluations) == 0 {
+		return state.Up, 0, false
+	}
+	return evaluations[0].Direction, evaluations[0].Value, true
+}
+
+type cacheKey struct {
+	board string
+	depth int
+}
+
+// search holds the state of a single search, so that a Solver can be
+// used by multiple goroutines at once.
+type search struct {
+	solver *Solver
+	// cache holds the values of chance nodes, as different move orders
+	// often lead to the same board.
+	cache map[cacheKey]float64
+}
+
+// chance averages over all possible spawns on the board. depth is the
+// number of player moves still to be searched.
+func (search *search) chance(board state.Board, depth int, probability float64) float64 {
+	if depth <= 0 || probability < search.solver.MinProbability {
+		return search.solver.Heuristic(board)
+	}
+
+	key := cacheKey{board: boardKey(board), depth: depth}
+	if value, cached := search.cache[key]; cached {
+		return value
+	}
+
+	var totalWeight float64
+	for _, entry := range search.solver.SpawnPolicy {
+		totalWeight += entry.Weight
+	}
+
+	cells := board.EmptyCells()
+	var expected float64
+	for _, cell := range cells {
+		for _, entry := range search.solver.SpawnPolicy {
+			if entry.Weight <= 0 {
+				continue
+			}
+			spawnProbability := entry.Weight / totalWeight / float64(len(cells))
+			expected += spawnProbability * search.max(board.Spawn(cell, entry.Value), depth, probability*spawnProbability)
+		}
+	}
+
+	search.cache[key] = expected
+	return expected
+}
+
+// max picks the best move on the board.
+func (search *search) max(board state.Board, depth int, probability float64) float64 {
+	best := math.Inf(-1)
+	for _, direction := range state.Directions {
+		moved, result := board.Move(direction)
+		if !result.Changed {
+			continue
+		}
+		if value := search.chance(moved, depth-1, probability); value > best {
+			best = value
+		}
+	}
+
+	if math.IsInf(best, -1) {
+		return search.solver.Heuristic(board) - lossPenalty
+	}
+	return best
+}
+
+// boardKey encodes the board as a string, one byte per cell.
+func boardKey(board state.Board) string {
+	key := make([]byte, 0, board.Width()*board.Height())
+	for _, row := range board {
+		for _, cell := range row {
+			key = append(key, byte(exponent(cell)))
+		}
+	}
+	return string(key)
+}
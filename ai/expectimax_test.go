@@ -0,0 +1,72 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func TestSolver_NoMove(t *testing.T) {
+	board := state.Board{
+		{2, 4},
+		{4, 2},
+	}
+	if _, _, ok := NewSolver(2).BestMove(board); ok {
+		t.Error("Expected no move on a terminal board")
+	}
+}
+
+func TestSolver_OnlyMove(t *testing.T) {
+	board := state.Board{
+		{2, 4, 8},
+		{4, 8, 16},
+		{8, 16, 0},
+	}
+	direction, _, ok := NewSolver(3).BestMove(board)
+	if !ok || (direction != state.Down && direction != state.Right) {
+		t.Errorf("Expected down or right, got %s", direction)
+	}
+}
+
+func TestSolver_Evaluate(t *testing.T) {
+	board := state.Board{
+		{0, 0, 0, 2},
+		{0, 0, 0, 4},
+		{0, 2, 8, 16},
+		{2, 8, 64, 128},
+	}
+	evaluations := NewSolver(2).Evaluate(board)
+	if len(evaluations) != 2 {
+		t.Fatalf("Expected 2 possible moves, got %v", evaluations)
+	}
+	for index := 1; index < len(evaluations); index++ {
+		if evaluations[index].Value > evaluations[index-1].Value {
+			t.Errorf("Evaluations aren't sorted: %v", evaluations)
+		}
+	}
+	//Moving up would pull the biggest tiles out of the corner.
+	if evaluations[len(evaluations)-1].Direction != state.Up {
+		t.Errorf("Expected up to be the worst move, got %v", evaluations)
+	}
+}
+
+func TestSolver_Plays(t *testing.T) {
+	session, err := state.NewGameSession(nil, state.DefaultRules(), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	solver := NewSolver(2)
+	for !session.GameOver && session.GameBoard.MaxTile() < 512 {
+		direction, _, ok := solver.BestMove(session.GameBoard)
+		if !ok {
+			t.Fatal("Solver found no move, even though the game isn't over")
+		}
+		if !session.Move(direction).Changed {
+			t.Fatalf("Solver chose %s, which doesn't change the board", direction)
+		}
+	}
+	if session.GameBoard.MaxTile() < 512 {
+		t.Errorf("Expected the solver to reach 512, but it lost with %d", session.GameBoard.MaxTile())
+	}
+}
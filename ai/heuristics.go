@@ -0,0 +1,155 @@
+package ai
+
+import (
+	"math/bits"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Heuristic rates a board from the player's point of view, with higher
+// values being better. Heuristics must not modify the board.
+type Heuristic func(board state.Board) float64
+
+// WeightedHeuristic is a single term of a heuristic created by Combine.
+type WeightedHeuristic struct {
+	Heuristic Heuristic
+	Weight    float64
+}
+
+// Combine produces a heuristic that sums up the weighted values of all
+// given heuristics.
+func Combine(terms ...WeightedHeuristic) Heuristic {
+	return func(board state.Board) float64 {
+		var value float64
+		for _, term := range terms {
+			value += term.Weight * term.Heuristic(board)
+		}
+		return value
+	}
+}
+
+// DefaultHeuristic combines all heuristics of this package with weights
+// that work well on the classic 4x4 board.
+func DefaultHeuristic() Heuristic {
+	return Combine(
+		WeightedHeuristic{Heuristic: EmptyCells, Weight: 2.7},
+		WeightedHeuristic{Heuristic: Monotonicity, Weight: 1},
+		WeightedHeuristic{Heuristic: Smoothness, Weight: 0.1},
+		WeightedHeuristic{Heuristic: CornerWeight, Weight: 0.5},
+	)
+}
+
+// exponent returns the power of two of a tile, so 1 for a 2 and 0 for an
+// empty cell.
+func exponent(value uint) float64 {
+	if value == 0 {
+		return 0
+	}
+	return float64(bits.Len(value) - 1)
+}
+
+// EmptyCells counts the free cells, as more free cells mean more room to
+// manoeuvre.
+func EmptyCells(board state.Board) float64 {
+	return float64(len(board.EmptyCells()))
+}
+
+// Monotonicity penalizes rows and columns whose tiles don't steadily
+// increase or decrease. Each line is penalized by the smaller of its
+// total increase and total decrease, measured in powers of two. The
+// result is never positive.
+func Monotonicity(board state.Board) float64 {
+	var penalty float64
+	lineValue := func(length int, at func(index int) uint) {
+		var increase, decrease float64
+		for index := 1; index < length; index++ {
+			previous, current := exponent(at(index-1)), exponent(at(index))
+			if current > previous {
+				increase += current - previous
+			} else {
+				decrease += previous - current
+			}
+		}
+		if increase < decrease {
+			penalty += increase
+		} else {
+			penalty += decrease
+		}
+	}
+
+	for row := 0; row < board.Height(); row++ {
+		lineValue(board.Width(), func(column int) uint { return board[row][column] })
+	}
+	for column := 0; column < board.Width(); column++ {
+		lineValue(board.Height(), func(row int) uint { return board[row][column] })
+	}
+	return -penalty
+}
+
+// Smoothness penalizes neighbouring tiles of very different values, as
+// they are hard to merge. Empty cells are skipped, so tiles separated by
+// empty cells count as neighbours. The result is never positive.
+func Smoothness(board state.Board) float64 {
+	var penalty float64
+	addLine := func(length int, at func(index int) uint) {
+		var previous float64
+		for index := 0; index < length; index++ {
+			value := at(index)
+			if value == 0 {
+				continue
+			}
+			current := exponent(value)
+			if previous != 0 {
+				if current > previous {
+					penalty += current - previous
+				} else {
+					penalty += previous - current
+				}
+			}
+			previous = current
+		}
+	}
+
+	for row := 0; row < board.Height(); row++ {
+		addLine(board.Width(), func(column int) uint { return board[row][column] })
+	}
+	for column := 0; column < board.Width(); column++ {
+		addLine(board.Height(), func(row int) uint { return board[row][column] })
+	}
+	return -penalty
+}
+
+// CornerWeight rewards keeping big tiles close to a corner. Each tile is
+// weighted by its closeness to the corner, using the corner that yields
+// the best value.
+func CornerWeight(board state.Board) float64 {
+	width, height := board.Width(), board.Height()
+	maxDistance := float64(width + height - 2)
+
+	var best float64
+	for _, corner := range []state.Position{
+		{Row: 0, Column: 0},
+		{Row: 0, Column: width - 1},
+		{Row: height - 1, Column: 0},
+		{Row: height - 1, Column: width - 1},
+	} {
+		var value float64
+		for row := 0; row < height; row++ {
+			for column := 0; column < width; column++ {
+				distance := float64(abs(row-corner.Row) + abs(column-corner.Column))
+				value += exponent(board[row][column]) * (maxDistance - distance) / maxDistance
+			}
+		}
+		if value > best {
+			best = value
+		}
+	}
+	return best
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
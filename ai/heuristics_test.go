@@ -0,0 +1,77 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func TestHeuristics(t *testing.T) {
+	tests := []struct {
+		name      string
+		heuristic Heuristic
+		board     state.Board
+		expected  float64
+	}{
+		{
+			name:      "empty cells",
+			heuristic: EmptyCells,
+			board:     state.Board{{2, 0}, {0, 4}},
+			expected:  2,
+		},
+		{
+			name:      "monotonic",
+			heuristic: Monotonicity,
+			board:     state.Board{{8, 4, 2, 0}, {4, 2, 0, 0}},
+			expected:  0,
+		},
+		{
+			name:      "not monotonic",
+			heuristic: Monotonicity,
+			board:     state.Board{{2, 8, 2}, {0, 0, 0}, {0, 0, 0}},
+			expected:  -2,
+		},
+		{
+			name:      "smoothness skips empty cells",
+			heuristic: Smoothness,
+			board:     state.Board{{2, 0, 8}},
+			expected:  -2,
+		},
+		{
+			name:      "smooth",
+			heuristic: Smoothness,
+			board:     state.Board{{4, 4}, {4, 4}},
+			expected:  0,
+		},
+		{
+			name:      "any corner",
+			heuristic: CornerWeight,
+			board:     state.Board{{0, 0, 0}, {0, 0, 0}, {0, 0, 8}},
+			expected:  3,
+		},
+		{
+			name:      "center",
+			heuristic: CornerWeight,
+			board:     state.Board{{0, 0, 0}, {0, 8, 0}, {0, 0, 0}},
+			expected:  1.5,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if value := test.heuristic(test.board); value != test.expected {
+				t.Errorf("Expected %g, got %g", test.expected, value)
+			}
+		})
+	}
+}
+
+func TestCombine(t *testing.T) {
+	heuristic := Combine(
+		WeightedHeuristic{Heuristic: EmptyCells, Weight: 2},
+		WeightedHeuristic{Heuristic: Smoothness, Weight: 0.5},
+	)
+	if value := heuristic(state.Board{{2, 0, 8}}); value != 1 {
+		t.Errorf("Expected 1, got %g", value)
+	}
+}
@@ -0,0 +1,115 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// DefaultDepth is the search depth of the "expectimax" strategy. It's a
+// good trade-off between strength and speed on the classic board.
+const DefaultDepth = 2
+
+// Strategy picks moves. Implementations must be safe for concurrent use.
+type Strategy interface {
+	// NextMove returns the move to play on the board. If no move is
+	// possible, false is returned.
+	NextMove(board state.Board) (state.Direction, bool)
+}
+
+// StrategyNames are the names accepted by NewStrategy.
+var StrategyNames = []string{"random", "greedy", "corner", "expectimax"}
+
+// NewStrategy produces the strategy with the given name, set up for
+// games played by the given rules. The seed is only used by strategies
+// that make random decisions.
+func NewStrategy(name string, rules state.Rules, seed int64) (Strategy, error) {
+	switch name {
+	case "random":
+		return NewRandom(seed), nil
+	case "corner":
+		return Corner{}, nil
+	case "greedy":
+		return Greedy{}, nil
+	case "expectimax":
+		solver := NewSolver(DefaultDepth)
+		solver.SpawnPolicy = rules.SpawnPolicy
+		return solver, nil
+	}
+	return nil, fmt.Errorf("unknown strategy %q, expected one of %s", name, strings.Join(StrategyNames, ", "))
+}
+
+// Greedy picks the move that gains the most points right away. Ties are
+// decided by the order of state.Directions.
+type Greedy struct{}
+
+// NextMove implements Strategy.
+func (Greedy) NextMove(board state.Board) (state.Direction, bool) {
+	var best state.Direction
+	var bestScore uint
+	found := false
+	for _, direction := range state.Directions {
+		_, result := board.Move(direction)
+		if result.Changed && (!found || result.ScoreGained > bestScore) {
+			best, bestScore, found = direction, result.ScoreGained, true
+		}
+	}
+	return best, found
+}
+
+// NextMove implements Strategy by playing the best move.
+func (solver *Solver) NextMove(board state.Board) (state.Direction, bool) {
+	direction, _, ok := solver.BestMove(board)
+	return direction, ok
+}
+
+// Random picks any move that changes the board. It serves as a baseline
+// for other strategies.
+type Random struct {
+	mutex  sync.Mutex
+	random *rand.Rand
+}
+
+// NewRandom produces a Random strategy. Two strategies with the same seed
+// make the same decisions.
+func NewRandom(seed int64) *Random {
+	return &Random{random: rand.New(rand.NewSource(seed))}
+}
+
+// NextMove implements Strategy.
+func (random *Random) NextMove(board state.Board) (state.Direction, bool) {
+	possible := make([]state.Direction, 0, len(state.Directions))
+	for _, direction := range state.Directions {
+		if board.CanMove(direction) {
+			possible = append(possible, direction)
+		}
+	}
+	if len(possible) == 0 {
+		return state.Up, false
+	}
+
+	random.mutex.Lock()
+	defer random.mutex.Unlock()
+	return possible[random.random.Intn(len(possible))], true
+}
+
+// cornerPriority keeps the biggest tiles in the bottom left corner, only
+// moving up if nothing else is possible.
+var cornerPriority = []state.Direction{state.Down, state.Left, state.Right, state.Up}
+
+// Corner is the well-known strategy of piling up tiles in a corner by
+// preferring two directions over the others.
+type Corner struct{}
+
+// NextMove implements Strategy.
+func (Corner) NextMove(board state.Board) (state.Direction, bool) {
+	for _, direction := range cornerPriority {
+		if board.CanMove(direction) {
+			return direction, true
+		}
+	}
+	return state.Up, false
+}
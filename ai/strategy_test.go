@@ -0,0 +1,75 @@
+package ai
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func TestGreedy(t *testing.T) {
+	board := state.Board{
+		{2, 0, 0},
+		{2, 0, 0},
+		{8, 8, 0},
+	}
+	if direction, ok := (Greedy{}).NextMove(board); !ok || direction != state.Left {
+		t.Errorf("Expected left, got %s", direction)
+	}
+
+	if _, ok := (Greedy{}).NextMove(state.Board{{2, 4}, {4, 2}}); ok {
+		t.Error("Expected no move on a terminal board")
+	}
+}
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range StrategyNames {
+		if _, err := NewStrategy(name, state.DefaultRules(), 1); err != nil {
+			t.Errorf("Unexpected error for %q: %s", name, err)
+		}
+	}
+	if _, err := NewStrategy("magic", state.DefaultRules(), 1); err == nil {
+		t.Error("Expected error for unknown strategy")
+	}
+}
+
+func TestRandom(t *testing.T) {
+	board := state.Board{
+		{2, 4, 0},
+		{4, 2, 0},
+		{2, 4, 0},
+	}
+	first, second := NewRandom(7), NewRandom(7)
+	for index := 0; index < 20; index++ {
+		direction, ok := first.NextMove(board)
+		if !ok || direction != state.Right {
+			t.Fatalf("Expected the only possible move right, got %s", direction)
+		}
+		second.NextMove(board)
+	}
+
+	board = state.Board{{2, 0}, {0, 0}}
+	for index := 0; index < 20; index++ {
+		firstDirection, _ := first.NextMove(board)
+		secondDirection, _ := second.NextMove(board)
+		if firstDirection != secondDirection {
+			t.Fatal("Strategies with the same seed made different decisions")
+		}
+	}
+}
+
+func TestCorner(t *testing.T) {
+	tests := []struct {
+		board    state.Board
+		expected state.Direction
+	}{
+		{board: state.Board{{2, 0}, {0, 0}}, expected: state.Down},
+		{board: state.Board{{0, 0}, {0, 2}}, expected: state.Left},
+		{board: state.Board{{0, 0}, {2, 0}}, expected: state.Right},
+		{board: state.Board{{0, 0}, {4, 2}}, expected: state.Up},
+	}
+	for _, test := range tests {
+		if direction, ok := (Corner{}).NextMove(test.board); !ok || direction != test.expected {
+			t.Errorf("Expected %s on %v, got %s", test.expected, test.board, direction)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"math"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// slideDuration is the time tiles take to slide to their new cell.
+	slideDuration = 100 * time.Millisecond
+	// popDuration is the time merged tiles pop and spawned tiles grow,
+	// after all tiles have arrived.
+	popDuration = 80 * time.Millisecond
+	// frameDuration is the time between two frames while animating.
+	frameDuration = 16 * time.Millisecond
+)
+
+// animation shows a single move, based on the tile movements described by
+// its MoveResult.
+type animation struct {
+	result state.MoveResult
+	// board is the board after the move. The animation is only shown as
+	// long as the board doesn't change.
+	board state.Board
+	start time.Time
+}
+
+func newAnimation(result state.MoveResult, board state.Board) *animation {
+	return &animation{result: result, board: board.Copy(), start: time.Now()}
+}
+
+// running indicates whether the animation still needs more frames.
+func (animation *animation) running(now time.Time) bool {
+	return now.Sub(animation.start) < slideDuration+popDuration
+}
+
+// draw draws the frame for the given point in time. First, the tiles
+// slide from their old to their new cell, with merging tiles keeping their
+// old value until they arrive. Then, merged tiles pop and the spawned tile
+// grows.
+func (animation *animation) draw(screen tcell.Screen, layout layout, now time.Time) {
+	elapsed := now.Sub(animation.start)
+	if elapsed < slideDuration {
+		animation.drawSlide(screen, layout, float64(elapsed)/float64(slideDuration))
+	} else {
+		progress := math.Min(1, float64(elapsed-slideDuration)/float64(popDuration))
+		animation.drawPop(screen, layout, progress)
+	}
+}
+
+func (animation *animation) drawSlide(screen tcell.Screen, layout layout, progress float64) {
+	result := animation.result
+	moving := make(map[state.Position]bool)
+	for _, move := range result.Moves {
+		moving[move.To] = true
+	}
+	for _, merge := range result.Merges {
+		moving[merge.To] = true
+	}
+	if result.Spawn != nil {
+		moving[result.Spawn.Position] = true
+	}
+
+	//Tiles that didn't move are drawn in place, the rest of the board is
+	//empty for the moving tiles to slide over.
+	layout.drawGaps(screen, animation.board)
+	for rowIndex, row := range animation.board {
+		for cellIndex, cell := range row {
+			position := state.Position{Row: rowIndex, Column: cellIndex}
+			x, y := layout.cellPosition(position)
+			if moving[position] {
+				layout.drawTile(screen, x, y, layout.cellWidth, layout.cellHeight, 0)
+			} else {
+				layout.drawTile(screen, x, y, layout.cellWidth, layout.cellHeight, cell)
+			}
+		}
+	}
+
+	for _, move := range result.Moves {
+		drawSlidingTile(screen, layout, move.From, move.To, move.Value, progress)
+	}
+	for _, merge := range result.Merges {
+		for _, from := range merge.From {
+			drawSlidingTile(screen, layout, from, merge.To, merge.Value/2, progress)
+		}
+	}
+}
+
+func drawSlidingTile(screen tcell.Screen, layout layout, from, to state.Position, value uint, progress float64) {
+	fromX, fromY := layout.cellPosition(from)
+	toX, toY := layout.cellPosition(to)
+	x := fromX + int(math.Round(float64(toX-fromX)*progress))
+	y := fromY + int(math.Round(float64(toY-fromY)*progress))
+	layout.drawTile(screen, x, y, layout.cellWidth, layout.cellHeight, value)
+}
+
+func (animation *animation) drawPop(screen tcell.Screen, layout layout, progress float64) {
+	board := animation.board
+	spawn := animation.result.Spawn
+	if spawn != nil {
+		//The spawned tile grows separately.
+		board = board.Copy()
+		board[spawn.Row][spawn.Column] = 0
+	}
+	layout.drawBoard(screen, board)
+
+	//Merged tiles briefly grow into the gaps next to them.
+	if progress < 1 {
+		for _, merge := range animation.result.Merges {
+			x, y := layout.cellPosition(merge.To)
+			layout.drawTile(screen, x-1, y, layout.cellWidth+2, layout.cellHeight, merge.Value)
+		}
+	}
+
+	if spawn != nil {
+		width := int(math.Max(1, math.Round(float64(layout.cellWidth)*progress)))
+		height := int(math.Max(1, math.Round(float64(layout.cellHeight)*progress)))
+		x, y := layout.cellPosition(spawn.Position)
+		layout.drawTile(screen, x+(layout.cellWidth-width)/2, y+(layout.cellHeight-height)/2, width, height, spawn.Value)
+	}
+}
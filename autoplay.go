@@ -0,0 +1,185 @@
+package main
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// failingStrategy is implemented by strategies that can fail to provide a
+// move, such as engines.
+type failingStrategy interface {
+	// Err returns the reason the last move couldn't be provided.
+	Err() error
+}
+
+// autoplayer plays the game using a strategy, instead of waiting for key
+// presses. The player can pause it, step through the moves, change the
+// speed and take over at any time.
+type autoplayer struct {
+	mutex    sync.Mutex
+	name     string
+	strategy ai.Strategy
+	// active is false once the player has taken over.
+	active     bool
+	paused     bool
+	speedIndex int
+	// changed wakes up the autoplayer whenever any of the settings
+	// changed, so that the new speed applies right away.
+	changed chan bool
+
+	// current returns the session that is currently being played, as it
+	// changes on restarts.
+	current func() *state.GameSession
+	// play is called with the lock of the session held and has to
+	// perform the move.
+	play func(state.Direction)
+}
+
+func newAutoplayer(name string, strategy ai.Strategy, speed float64, current func() *state.GameSession, play func(state.Direction)) *autoplayer {
+	speedIndex := len(playbackSpeeds) - 1
+	for index, available := range playbackSpeeds {
+		if available >= speed {
+			speedIndex = index
+			break
+		}
+	}
+
+	return &autoplayer{
+		name:       name,
+		strategy:   strategy,
+		active:     true,
+		speedIndex: speedIndex,
+		changed:    make(chan bool, 1),
+		current:    current,
+		play:       play,
+	}
+}
+
+func (autoplayer *autoplayer) update(change func()) {
+	autoplayer.mutex.Lock()
+	change()
+	autoplayer.mutex.Unlock()
+
+	select {
+	case autoplayer.changed <- true:
+	default:
+	}
+}
+
+// takeOver stops the autoplayer, as the player wants to play manually.
+func (autoplayer *autoplayer) takeOver() {
+	autoplayer.update(func() {
+		autoplayer.active = false
+	})
+}
+
+// togglePause pauses or resumes playing. If the player has taken over,
+// the autoplayer resumes playing.
+func (autoplayer *autoplayer) togglePause() {
+	autoplayer.update(func() {
+		if autoplayer.active {
+			autoplayer.paused = !autoplayer.paused
+		} else {
+			autoplayer.active = true
+			autoplayer.paused = false
+		}
+	})
+}
+
+// stepOnce pauses the autoplayer and plays a single move in the
+// background.
+func (autoplayer *autoplayer) stepOnce() {
+	autoplayer.update(func() {
+		autoplayer.active = true
+		autoplayer.paused = true
+	})
+	go autoplayer.step(true)
+}
+
+func (autoplayer *autoplayer) changeSpeed(delta int) {
+	autoplayer.update(func() {
+		speedIndex := autoplayer.speedIndex + delta
+		if speedIndex >= 0 && speedIndex < len(playbackSpeeds) {
+			autoplayer.speedIndex = speedIndex
+		}
+	})
+}
+
+// status describes the state of the autoplayer for the status line.
+func (autoplayer *autoplayer) status() string {
+	autoplayer.mutex.Lock()
+	defer autoplayer.mutex.Unlock()
+
+	if !autoplayer.active {
+		return fmt.Sprintf("Autoplay (%s) off; Space: Resume", autoplayer.name)
+	}
+	status := fmt.Sprintf("Autoplay (%s): %g moves/s", autoplayer.name, playbackSpeeds[autoplayer.speedIndex])
+	if autoplayer.paused {
+		status += " [paused]"
+	}
+	if failing, isFailing := autoplayer.strategy.(failingStrategy); isFailing && failing.Err() != nil {
+		status += fmt.Sprintf(" (%s)", failing.Err())
+	}
+	return status + "; Space: Pause  .: Step  +/-: Speed  Arrows: Take over"
+}
+
+// run plays moves on the current session until the program exits.
+func (autoplayer *autoplayer) run() {
+	for {
+		autoplayer.mutex.Lock()
+		playing := autoplayer.active && !autoplayer.paused
+		delay := playbackDelay(autoplayer.speedIndex)
+		autoplayer.mutex.Unlock()
+
+		if playing {
+			select {
+			case <-time.After(delay):
+				autoplayer.step(false)
+			case <-autoplayer.changed:
+			}
+		} else {
+			<-autoplayer.changed
+		}
+	}
+}
+
+// step plays a single move. The search happens on a copy of the board,
+// without holding the lock of the session, so that a slow strategy
+// doesn't block the UI. If the player changed the game, took over or
+// paused in the meantime, the move is dropped. single is set for moves
+// requested via stepOnce, which are played while paused.
+func (autoplayer *autoplayer) step(single bool) {
+	session := autoplayer.current()
+	session.Mutex.Lock()
+	if session.GameOver || session.AwaitingDecision() {
+		session.Mutex.Unlock()
+		return
+	}
+	board := session.GameBoard.Copy()
+	session.Mutex.Unlock()
+
+	direction, ok := autoplayer.strategy.NextMove(board)
+	if !ok {
+		//Retrying right away would most likely fail again.
+		if failing, isFailing := autoplayer.strategy.(failingStrategy); isFailing && failing.Err() != nil {
+			autoplayer.update(func() {
+				autoplayer.paused = true
+			})
+		}
+		return
+	}
+
+	session.Mutex.Lock()
+	autoplayer.mutex.Lock()
+	wanted := autoplayer.active && (!autoplayer.paused || single)
+	autoplayer.mutex.Unlock()
+	if wanted && session == autoplayer.current() && reflect.DeepEqual(board, session.GameBoard) {
+		autoplayer.play(direction)
+	}
+	session.Mutex.Unlock()
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// blockingStrategy reports each search and then waits until it is allowed
+// to return a move, so that tests can act while it is searching.
+type blockingStrategy struct {
+	searching chan bool
+	proceed   chan bool
+}
+
+func (strategy blockingStrategy) NextMove(board state.Board) (state.Direction, bool) {
+	strategy.searching <- true
+	<-strategy.proceed
+	for _, direction := range state.Directions {
+		if board.CanMove(direction) {
+			return direction, true
+		}
+	}
+	return state.Up, false
+}
+
+func TestAutoplayer_TakeOverDuringSearch(t *testing.T) {
+	session, err := state.NewGameSession(nil, state.DefaultRules(), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	strategy := blockingStrategy{searching: make(chan bool), proceed: make(chan bool)}
+	played := 0
+	autoplayer := newAutoplayer("test", strategy, 1,
+		func() *state.GameSession { return session },
+		func(direction state.Direction) {
+			played++
+			session.Move(direction)
+		})
+
+	testCases := []struct {
+		name     string
+		single   bool
+		interact func()
+		expected int
+	}{
+		{"playing", false, func() {}, 1},
+		{"taking over", false, autoplayer.takeOver, 0},
+		{"pausing", false, autoplayer.togglePause, 0},
+		{"stepping while paused", true, func() {}, 1},
+		{"taking over while stepping", true, autoplayer.takeOver, 0},
+	}
+	for _, testCase := range testCases {
+		played = 0
+		//Starts actively playing, as if freshly created.
+		autoplayer.active, autoplayer.paused = true, testCase.single
+
+		done := make(chan bool)
+		go func(single bool) {
+			autoplayer.step(single)
+			done <- true
+		}(testCase.single)
+		<-strategy.searching
+		testCase.interact()
+		strategy.proceed <- true
+		<-done
+
+		if played != testCase.expected {
+			t.Errorf("%s: expected %d moves to be played, got %d", testCase.name, testCase.expected, played)
+		}
+	}
+}
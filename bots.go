@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/engine"
+	"github.com/Bios-Marcel/2048-terminal/simulation"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// engineStartTimeout is the time an engine has for starting up and
+// replying to the handshake.
+const engineStartTimeout = 10 * time.Second
+
+// botStrategy lets an engine play via the ai.Strategy interface, for
+// example to drive the autoplayer.
+type botStrategy struct {
+	engine    *engine.Engine
+	timeLimit time.Duration
+
+	mutex sync.Mutex
+	// lastError is the reason the engine couldn't provide the last move.
+	lastError error
+}
+
+// NextMove implements ai.Strategy. If the engine fails to provide a
+// legal move, false is returned and the reason is available via Err.
+func (bot *botStrategy) NextMove(board state.Board) (state.Direction, bool) {
+	direction, err := bot.engine.NextMove(board, bot.timeLimit)
+
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
+	bot.lastError = err
+	return direction, err == nil
+}
+
+// Err returns the reason the engine couldn't provide the last move.
+func (bot *botStrategy) Err() error {
+	bot.mutex.Lock()
+	defer bot.mutex.Unlock()
+	return bot.lastError
+}
+
+// runBot implements the "bot" subcommand, which lets an engine play games
+// without a terminal UI, for example for tournaments.
+func runBot(args []string) int {
+	flags := flag.NewFlagSet("bot", flag.ExitOnError)
+	games := flags.Int("games", 10, "amount of games to play")
+	seed := flags.Int64("seed", 0, "seed of the first game, the following games use the next seeds (default random)")
+	size := flags.String("size", "4", "size of the board, either N for a square board or WIDTHxHEIGHT")
+	spawn := flags.String("spawn", "2:0.9,4:0.1", "weighted tile values to spawn, in the form VALUE:WEIGHT,...")
+	timeLimit := flags.Duration("time-limit", time.Second, "time the engine has for each move")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal bot [flags] <command> [arguments]")
+		fmt.Fprintln(flags.Output(), "Lets an engine play games and prints the results. See the README for the protocol.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	rules := state.DefaultRules()
+	rules.UndoLimit = 0
+	var err error
+	if rules.Width, rules.Height, err = parseSize(*size); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --size: %s\n", err)
+		return 2
+	}
+	if rules.SpawnPolicy, err = parseSpawnPolicy(*spawn); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --spawn: %s\n", err)
+		return 2
+	}
+	firstSeed := *seed
+	if !isFlagSet(flags, "seed") {
+		firstSeed = state.RandomSeed()
+	}
+
+	botEngine, err := engine.Start(flags.Args(), rules, os.Stderr, engineStartTimeout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer botEngine.Close()
+	bot := &botStrategy{engine: botEngine, timeLimit: *timeLimit}
+
+	name := botEngine.Name
+	if name == "" {
+		name = flags.Arg(0)
+	}
+
+	start := time.Now()
+	played := make([]simulation.Game, 0, *games)
+	var forfeits int
+	for index := 0; index < *games; index++ {
+		game, err := simulation.Play(rules, firstSeed+int64(index), bot)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		played = append(played, game)
+
+		result := fmt.Sprintf("Game %d (seed %d): score %d, max tile %d, %d moves",
+			index+1, game.Seed, game.Score, game.MaxTile, game.Moves)
+		if botError := bot.Err(); botError != nil {
+			forfeits++
+			fmt.Printf("%s, forfeited: %s\n", result, botError)
+			if botError == engine.ErrTimeout {
+				fmt.Fprintln(os.Stderr, "Stopping, as the engine has been stopped after a timeout.")
+				break
+			}
+			continue
+		}
+		fmt.Println(result)
+	}
+	fmt.Println()
+
+	printSimulationText(os.Stdout, simulationReport{
+		Strategy: "bot " + name,
+		Seed:     firstSeed,
+		Workers:  1,
+		Width:    rules.Width,
+		Height:   rules.Height,
+		Stats:    simulation.Aggregate(played, time.Since(start)),
+	})
+	fmt.Printf("Forfeits: %d\n", forfeits)
+	if forfeits > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"os"
+
+	"github.com/Bios-Marcel/2048-terminal/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+// colorSupport describes how many colors a terminal can display.
+type colorSupport int
+
+const (
+	monochrome colorSupport = iota
+	// eightColors lacks the bright variants of the basic colors.
+	eightColors
+	basicColors
+	paletteColors
+	trueColors
+)
+
+// detectColorSupport asks the terminal for its amount of colors. Colors
+// are turned off completely if NO_COLOR is set, see https://no-color.org.
+func detectColorSupport(screen tcell.Screen) colorSupport {
+	if os.Getenv("NO_COLOR") != "" {
+		return monochrome
+	}
+
+	colors := screen.Colors()
+	switch {
+	case colors >= 1<<24:
+		return trueColors
+	case colors >= 256:
+		return paletteColors
+	case colors >= 16:
+		return basicColors
+	case colors >= 8:
+		return eightColors
+	}
+	return monochrome
+}
+
+// themeFor returns the name of the bundled theme that looks best with the
+// given color support.
+func themeFor(support colorSupport) string {
+	switch support {
+	case trueColors:
+		return theme.DefaultName
+	case paletteColors:
+		return "256"
+	case basicColors:
+		return "16"
+	case eightColors:
+		return "8"
+	}
+	return "mono"
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+// colorScreen is a screen that claims to support the given amount of
+// colors.
+type colorScreen struct {
+	tcell.Screen
+	colors int
+}
+
+func (screen colorScreen) Colors() int {
+	return screen.colors
+}
+
+func TestDetectColorSupport(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	testCases := []struct {
+		colors   int
+		expected colorSupport
+	}{
+		{0, monochrome},
+		{2, monochrome},
+		{8, eightColors},
+		{16, basicColors},
+		{88, basicColors},
+		{256, paletteColors},
+		{1 << 24, trueColors},
+	}
+	for _, testCase := range testCases {
+		if support := detectColorSupport(colorScreen{colors: testCase.colors}); support != testCase.expected {
+			t.Errorf("Expected %d for %d colors, got %d", testCase.expected, testCase.colors, support)
+		}
+	}
+
+	if support := detectColorSupport(newTestScreen(t, 10, 10)); support != paletteColors {
+		t.Errorf("Expected simulation screen to support the palette, got %d", support)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if support := detectColorSupport(colorScreen{colors: 1 << 24}); support != monochrome {
+		t.Errorf("Expected NO_COLOR to turn colors off, got %d", support)
+	}
+}
+
+func TestThemeFor(t *testing.T) {
+	testCases := map[colorSupport]string{
+		monochrome:    "mono",
+		eightColors:   "8",
+		basicColors:   "16",
+		paletteColors: "256",
+		trueColors:    theme.DefaultName,
+	}
+	for support, expected := range testCases {
+		name := themeFor(support)
+		if name != expected {
+			t.Errorf("Expected theme %s for %d, got %s", expected, support, name)
+		}
+		if _, found := theme.Find(name); !found {
+			t.Errorf("Theme %s for %d isn't bundled", name, support)
+		}
+	}
+
+	themes, index, err := loadThemes("", monochrome)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if themes[index].Name != "mono" {
+		t.Errorf("Expected mono theme to be chosen without colors, got %s", themes[index].Name)
+	}
+}
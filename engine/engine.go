@@ -0,0 +1,270 @@
+// Package engine lets external programs, so called engines or bots, play
+// the game. Engines are started as a subprocess and talk to the game via
+// text lines on stdin and stdout, similar to UCI for chess engines.
+//
+// The game sends these lines to the engine:
+//
+//	2048-engine 1
+//	rules {"width":4,"height":4,...}
+//	position 0,2,0,0/0,0,0,0/0,0,4,0/0,0,0,0
+//	go 100
+//	illegal move "sideways" isn't a direction
+//	quit
+//
+// "2048-engine" starts the session and names the protocol version. The
+// engine has to reply with "ready", optionally followed by its name. Next,
+// "rules" passes the JSON encoded state.Rules, which stay the same for all
+// games of the session.
+//
+// Each turn consists of "position", giving the board row by row separated
+// by '/', with the cells of a row separated by ',' and 0 for empty cells,
+// followed by "go" with the time limit in milliseconds. The engine has to
+// reply with "move" and a direction (up, down, left or right) within the
+// time limit. Moves that don't change the board are illegal. The engine is
+// told about illegal moves via "illegal" and the reason, which ends the
+// game. Engines that don't reply in time are stopped.
+//
+// "quit" asks the engine to exit. Apart from the replies described above,
+// engines may print lines starting with "info" or '#', which are ignored.
+package engine
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Version is the protocol version spoken by this package.
+const Version = 1
+
+// ErrTimeout is returned if the engine doesn't reply in time. The engine
+// is stopped in that case, as a late reply would be mistaken for the reply
+// to the next request.
+var ErrTimeout = errors.New("engine didn't reply in time")
+
+// IllegalMoveError is returned if the engine replies with something other
+// than a move that changes the board.
+type IllegalMoveError struct {
+	Reply  string
+	Reason string
+}
+
+func (err *IllegalMoveError) Error() string {
+	return fmt.Sprintf("illegal move %q: %s", err.Reply, err.Reason)
+}
+
+// Engine is a running engine process. It's safe for concurrent use,
+// requests are handled one at a time.
+type Engine struct {
+	// Name is the name the engine introduced itself with. It might be
+	// empty.
+	Name string
+
+	mutex   sync.Mutex
+	command *exec.Cmd
+	stdin   io.WriteCloser
+	replies chan string
+	// stopped is closed once the engine is being stopped. From then on,
+	// its replies are dropped.
+	stopped  chan struct{}
+	stopOnce sync.Once
+	// exited receives the result of waiting for the process to exit.
+	exited chan error
+	// err is set once the engine can't be used anymore.
+	err    error
+	closed bool
+}
+
+// Start launches the engine and performs the handshake, which has to
+// complete within the given timeout. Anything the engine prints to stderr
+// is written to the given writer, which might be nil to discard it.
+func Start(command []string, rules state.Rules, stderr io.Writer, timeout time.Duration) (*Engine, error) {
+	if len(command) == 0 {
+		return nil, errors.New("no engine command given")
+	}
+	rulesJSON, err := json.Marshal(rules)
+	if err != nil {
+		return nil, err
+	}
+
+	process := exec.Command(command[0], command[1:]...)
+	process.Stderr = stderr
+	stdin, err := process.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := process.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := process.Start(); err != nil {
+		return nil, err
+	}
+
+	engine := &Engine{
+		command: process,
+		stdin:   stdin,
+		replies: make(chan string),
+		stopped: make(chan struct{}),
+		exited:  make(chan error, 1),
+	}
+	go engine.readReplies(stdout)
+
+	if err := engine.send(fmt.Sprintf("2048-engine %d", Version)); err != nil {
+		engine.kill()
+		return nil, err
+	}
+	reply, err := engine.receive(timeout)
+	if err != nil {
+		engine.kill()
+		return nil, fmt.Errorf("handshake failed: %w", err)
+	}
+	if reply != "ready" && !strings.HasPrefix(reply, "ready ") {
+		engine.kill()
+		return nil, fmt.Errorf("handshake failed: expected \"ready\", but got %q", reply)
+	}
+	engine.Name = strings.TrimSpace(strings.TrimPrefix(reply, "ready"))
+
+	if err := engine.send("rules " + string(rulesJSON)); err != nil {
+		engine.kill()
+		return nil, err
+	}
+	return engine, nil
+}
+
+// readReplies forwards all relevant lines printed by the engine, until it
+// closes its stdout. Afterwards, it waits for the process to exit, as
+// that has to happen after reading all output.
+func (engine *Engine) readReplies(stdout io.Reader) {
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || line == "info" || strings.HasPrefix(line, "info ") || strings.HasPrefix(line, "#") {
+			continue
+		}
+		select {
+		case engine.replies <- line:
+		case <-engine.stopped:
+		}
+	}
+	close(engine.replies)
+	engine.exited <- engine.command.Wait()
+}
+
+func (engine *Engine) send(line string) error {
+	_, err := io.WriteString(engine.stdin, line+"\n")
+	return err
+}
+
+func (engine *Engine) receive(timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case reply, open := <-engine.replies:
+		if !open {
+			return "", errors.New("engine exited")
+		}
+		return reply, nil
+	case <-timer.C:
+		return "", ErrTimeout
+	}
+}
+
+// NextMove asks the engine for its move on the given board. Illegal moves
+// are reported to the engine and returned as *IllegalMoveError. If the
+// engine doesn't reply within the time limit, ErrTimeout is returned and
+// the engine is stopped.
+func (engine *Engine) NextMove(board state.Board, timeLimit time.Duration) (state.Direction, error) {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	if engine.err != nil {
+		return state.Up, engine.err
+	}
+
+	err := engine.send("position " + board.Format())
+	if err == nil {
+		err = engine.send(fmt.Sprintf("go %d", timeLimit.Milliseconds()))
+	}
+	var reply string
+	if err == nil {
+		reply, err = engine.receive(timeLimit)
+	}
+	if err != nil {
+		engine.err = err
+		engine.kill()
+		return state.Up, err
+	}
+
+	direction, illegal := parseMove(reply, board)
+	if illegal != nil {
+		//The engine might already be gone, which is reported on the next
+		//request.
+		engine.send("illegal " + illegal.Reason)
+		return state.Up, illegal
+	}
+	return direction, nil
+}
+
+func parseMove(reply string, board state.Board) (state.Direction, *IllegalMoveError) {
+	fields := strings.Fields(reply)
+	if len(fields) != 2 || fields[0] != "move" {
+		return state.Up, &IllegalMoveError{Reply: reply, Reason: "expected \"move DIRECTION\""}
+	}
+
+	direction, err := state.ParseDirection(fields[1])
+	if err != nil {
+		return state.Up, &IllegalMoveError{Reply: reply, Reason: err.Error()}
+	}
+	if !board.CanMove(direction) {
+		return state.Up, &IllegalMoveError{Reply: reply, Reason: "the move doesn't change the board"}
+	}
+	return direction, nil
+}
+
+// Close asks the engine to quit and waits for it to exit. Engines that
+// don't exit within a second are killed.
+func (engine *Engine) Close() error {
+	engine.mutex.Lock()
+	defer engine.mutex.Unlock()
+
+	if engine.closed {
+		return nil
+	}
+	engine.closed = true
+	if engine.err == nil {
+		engine.send("quit")
+		engine.err = errors.New("engine has been closed")
+	}
+	engine.stdin.Close()
+	engine.stop()
+
+	select {
+	case err := <-engine.exited:
+		return err
+	case <-time.After(time.Second):
+		engine.command.Process.Kill()
+		return <-engine.exited
+	}
+}
+
+func (engine *Engine) stop() {
+	engine.stopOnce.Do(func() {
+		close(engine.stopped)
+	})
+}
+
+// kill stops the engine without waiting for it to exit.
+func (engine *Engine) kill() {
+	engine.stop()
+	engine.command.Process.Kill()
+}
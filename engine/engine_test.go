@@ -0,0 +1,129 @@
+package engine
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// TestHelperEngine isn't a real test. It's started as a subprocess by the
+// other tests and acts as an engine, behaving as told by ENGINE_HELPER.
+func TestHelperEngine(t *testing.T) {
+	behaviour := os.Getenv("ENGINE_HELPER")
+	if behaviour == "" {
+		return
+	}
+
+	var board state.Board
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		switch fields[0] {
+		case "2048-engine":
+			if behaviour != "silent" {
+				fmt.Println("ready helper")
+			}
+		case "position":
+			board, _ = state.ParseBoard(fields[1])
+		case "go":
+			switch behaviour {
+			case "illegal":
+				fmt.Println("move sideways")
+			case "timeout":
+			default:
+				fmt.Println("info thinking")
+				for _, direction := range state.Directions {
+					if board.CanMove(direction) {
+						fmt.Println("move", direction)
+						break
+					}
+				}
+			}
+		case "quit":
+			os.Exit(0)
+		}
+	}
+	os.Exit(0)
+}
+
+func startHelper(t *testing.T, behaviour string) (*Engine, error) {
+	t.Helper()
+	t.Setenv("ENGINE_HELPER", behaviour)
+	return Start([]string{os.Args[0], "-test.run=TestHelperEngine"}, state.DefaultRules(), nil, 5*time.Second)
+}
+
+func TestEngine_Play(t *testing.T) {
+	engine, err := startHelper(t, "play")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if engine.Name != "helper" {
+		t.Errorf("Expected name helper, got %q", engine.Name)
+	}
+
+	session, _ := state.NewGameSession(nil, state.DefaultRules(), 5)
+	for index := 0; index < 10 && !session.GameOver; index++ {
+		direction, err := engine.NextMove(session.GameBoard, 5*time.Second)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+		if !session.Move(direction).Changed {
+			t.Fatalf("Engine returned move %s, which doesn't change the board", direction)
+		}
+	}
+
+	if err := engine.Close(); err != nil {
+		t.Errorf("Unexpected error on close: %s", err)
+	}
+	if _, err := engine.NextMove(session.GameBoard, time.Second); err == nil {
+		t.Error("Expected error after closing the engine")
+	}
+}
+
+func TestEngine_Illegal(t *testing.T) {
+	engine, err := startHelper(t, "illegal")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer engine.Close()
+
+	_, err = engine.NextMove(state.Board{{2, 0}, {0, 0}}, 5*time.Second)
+	var illegal *IllegalMoveError
+	if !errors.As(err, &illegal) || illegal.Reply != "move sideways" {
+		t.Errorf("Expected illegal move error, got: %v", err)
+	}
+}
+
+func TestEngine_Timeout(t *testing.T) {
+	engine, err := startHelper(t, "timeout")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	defer engine.Close()
+
+	board := state.Board{{2, 0}, {0, 0}}
+	if _, err := engine.NextMove(board, 50*time.Millisecond); err != ErrTimeout {
+		t.Errorf("Expected timeout, got: %v", err)
+	}
+	if _, err := engine.NextMove(board, 50*time.Millisecond); err != ErrTimeout {
+		t.Errorf("Expected engine to stay stopped after a timeout, got: %v", err)
+	}
+}
+
+func TestEngine_FailedHandshake(t *testing.T) {
+	t.Setenv("ENGINE_HELPER", "silent")
+	_, err := Start([]string{os.Args[0], "-test.run=TestHelperEngine"}, state.DefaultRules(), nil, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "handshake") {
+		t.Errorf("Expected handshake error, got: %v", err)
+	}
+
+	if _, err := Start(nil, state.DefaultRules(), nil, time.Second); err == nil {
+		t.Error("Expected error without command")
+	}
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// hintDepth is kept low, so that hints show up instantly, even on big
+// boards.
+const hintDepth = 2
+
+// hint is a move recommended to the player.
+type hint struct {
+	// board is the board the hint has been computed for. The hint is
+	// only shown as long as the board doesn't change.
+	board       state.Board
+	evaluations []ai.Evaluation
+}
+
+// computeHint searches for the best move in the session and counts the
+// hint in the session. If no move is possible, nil is returned. The lock
+// of the session has to be held.
+func computeHint(session *state.GameSession) *hint {
+	if session.GameOver || session.AwaitingDecision() {
+		return nil
+	}
+
+	solver := ai.NewSolver(hintDepth)
+	solver.SpawnPolicy = session.Rules().SpawnPolicy
+	evaluations := solver.Evaluate(session.GameBoard)
+	if len(evaluations) == 0 {
+		return nil
+	}
+
+	session.RecordHint()
+	return &hint{board: session.GameBoard.Copy(), evaluations: evaluations}
+}
+
+// description names the recommended move and how much better it is
+// expected to be than the second best move.
+func (hint *hint) description() string {
+	best := hint.evaluations[0]
+	if len(hint.evaluations) == 1 {
+		return fmt.Sprintf("Hint: %s (only possible move)", best.Direction)
+	}
+	second := hint.evaluations[1]
+	return fmt.Sprintf("Hint: %s (%+.1f compared to %s)", best.Direction, best.Value-second.Value, second.Direction)
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+const (
+	// minCellWidth and maxCellWidth limit the cell width of the regular
+	// layout, where cells are half as high as they are wide, so that they
+	// look roughly square.
+	minCellWidth = 6
+	maxCellWidth = 16
+	// compactCellWidth is the cell width of the compact layout, where each
+	// cell is a single line high and there are no gaps between rows.
+	compactCellWidth = 5
+	// compactBorderWidth is added to compactCellWidth if the theme has
+	// borders, which are drawn as brackets around the value instead.
+	compactBorderWidth = 2
+)
+
+// layout describes where, in which size and with which theme the board
+// and the panel next to it are drawn.
+type layout struct {
+	theme *theme.Theme
+
+	cellWidth  int
+	cellHeight int
+	// gapX and gapY are the space between neighbouring cells.
+	gapX int
+	gapY int
+	// x and y are the top left corner of the board.
+	x int
+	y int
+	// panelX and panelY are the top left corner of the panel.
+	panelX int
+	panelY int
+	// panelAbove is set if there's no room for the panel right of the
+	// board. It's then drawn above the board instead, with as many
+	// entries per line as fit the screen.
+	panelAbove bool
+	// width and height are the space needed for everything, including
+	// the footer.
+	width  int
+	height int
+}
+
+// newLayout picks the biggest cells with which the board, the panel and
+// the given amount of lines below the board fit the screen, and centers
+// the result. The panel is put right of the board if possible, otherwise
+// above it. If not even the compact layout fits, it's returned anyway, but
+// not centered, and false is returned as well.
+func newLayout(screen tcell.Screen, theme *theme.Theme, board state.Board, panel []string, footerLines int) (layout, bool) {
+	screenWidth, screenHeight := screen.Size()
+	candidates := make([]layout, 0, (maxCellWidth-minCellWidth)/2+2)
+	for cellWidth := maxCellWidth; cellWidth >= minCellWidth; cellWidth -= 2 {
+		candidates = append(candidates, layout{theme: theme, cellWidth: cellWidth, cellHeight: cellWidth / 2, gapX: 2, gapY: 1})
+	}
+	compact := layout{theme: theme, cellWidth: compactCellWidth, cellHeight: 1, gapX: 1}
+	if theme.Borders {
+		compact.cellWidth += compactBorderWidth
+	}
+	candidates = append(candidates, compact)
+
+	var candidate layout
+	for _, candidate = range candidates {
+		for _, panelAbove := range []bool{false, true} {
+			candidate.arrange(board, panel, panelAbove, footerLines, screenWidth)
+			if candidate.width <= screenWidth && candidate.height <= screenHeight {
+				candidate.moveBy((screenWidth-candidate.width)/2, (screenHeight-candidate.height)/2)
+				return candidate, true
+			}
+		}
+	}
+	return candidate, false
+}
+
+// panelGap is the space between the board and the panel right of it.
+const panelGap = 3
+
+// panelSeparator separates the entries of the panel above the board.
+const panelSeparator = "  "
+
+// arrange places the board and the panel, starting in the top left corner
+// of the screen. A panel above the board is wrapped at the screen width.
+func (layout *layout) arrange(board state.Board, panel []string, panelAbove bool, footerLines, screenWidth int) {
+	layout.panelAbove = panelAbove
+	layout.x, layout.y, layout.panelX, layout.panelY = 0, 0, 0, 0
+	layout.width = layout.boardWidth(board)
+	layout.height = layout.boardHeight(board)
+	if len(panel) == 0 {
+		layout.height += footerLines
+		return
+	}
+
+	if panelAbove {
+		lines := joinPanel(panel, screenWidth)
+		//The panel is followed by a line of space.
+		layout.y = len(lines) + 1
+		layout.height += len(lines) + 1
+		if panelWidth := maxLength(lines); panelWidth > layout.width {
+			//The board is centered below the wider panel.
+			layout.x = (panelWidth - layout.width) / 2
+			layout.width = panelWidth
+		}
+	} else {
+		panelWidth := maxLength(panel)
+		layout.panelX = layout.width + panelGap
+		layout.width += panelGap + panelWidth
+		if len(panel) > layout.height {
+			layout.height = len(panel)
+		}
+	}
+	layout.height += footerLines
+}
+
+// joinPanel joins the entries of the panel into as few lines as possible,
+// without exceeding the given width. Entries that are too wide on their
+// own get a line each.
+func joinPanel(panel []string, width int) []string {
+	var lines []string
+	for _, entry := range panel {
+		last := len(lines) - 1
+		if last >= 0 && len([]rune(lines[last]+panelSeparator+entry)) <= width {
+			lines[last] += panelSeparator + entry
+		} else {
+			lines = append(lines, entry)
+		}
+	}
+	return lines
+}
+
+func maxLength(lines []string) int {
+	var length int
+	for _, line := range lines {
+		if lineLength := len([]rune(line)); lineLength > length {
+			length = lineLength
+		}
+	}
+	return length
+}
+
+func (layout *layout) moveBy(x, y int) {
+	layout.x += x
+	layout.y += y
+	layout.panelX += x
+	layout.panelY += y
+}
+
+// cellPosition returns the screen coordinates of the top left corner of
+// the given cell.
+func (layout layout) cellPosition(position state.Position) (int, int) {
+	return layout.x + position.Column*(layout.cellWidth+layout.gapX),
+		layout.y + position.Row*(layout.cellHeight+layout.gapY)
+}
+
+func (layout layout) boardWidth(board state.Board) int {
+	return layout.cellWidth*board.Width() + (board.Width()-1)*layout.gapX
+}
+
+func (layout layout) boardHeight(board state.Board) int {
+	return layout.cellHeight*board.Height() + (board.Height()-1)*layout.gapY
+}
+
+// footerY returns the line below the board, leaving one line of space.
+func (layout layout) footerY(board state.Board) int {
+	return layout.y + layout.boardHeight(board) + 1
+}
+
+// drawTooSmall asks for a bigger terminal, which needs to fit the given
+// layout.
+func drawTooSmall(screen tcell.Screen, layout layout) {
+	screenWidth, screenHeight := screen.Size()
+	lines := []string{
+		"Terminal too small",
+		fmt.Sprintf("Need %dx%d, have %dx%d", layout.width, layout.height, screenWidth, screenHeight),
+	}
+	for index, line := range lines {
+		x := (screenWidth - len(line)) / 2
+		if x < 0 {
+			x = 0
+		}
+		drawText(screen, x, (screenHeight-len(lines))/2+index, layout.theme.Panel, line)
+	}
+}
+
+// drawFooter draws the given lines below the board, aligned with its left
+// edge.
+func (layout layout) drawFooter(screen tcell.Screen, board state.Board, lines ...string) {
+	y := layout.footerY(board)
+	for index, line := range lines {
+		layout.drawLine(screen, layout.x, y+index, line)
+	}
+}
+
+// drawPanel draws the given lines right of the board, or joined into as
+// few lines as fit the screen above it.
+func (layout layout) drawPanel(screen tcell.Screen, lines ...string) {
+	if layout.panelAbove {
+		screenWidth, _ := screen.Size()
+		for index, line := range joinPanel(lines, screenWidth) {
+			layout.drawLine(screen, layout.panelX, layout.panelY+index, line)
+		}
+		return
+	}
+	for index, line := range lines {
+		drawText(screen, layout.panelX, layout.panelY+index, layout.theme.Panel, line)
+	}
+}
+
+// drawLine draws the text starting at the given position, unless that
+// would cut it off at the right edge of the screen.
+func (layout layout) drawLine(screen tcell.Screen, x, y int, text string) {
+	screenWidth, _ := screen.Size()
+	if overflow := x + len([]rune(text)) - screenWidth; overflow > 0 {
+		x -= overflow
+	}
+	if x < 0 {
+		x = 0
+	}
+	drawText(screen, x, y, layout.theme.Panel, text)
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+func newTestScreen(t *testing.T, width, height int) tcell.SimulationScreen {
+	screen := tcell.NewSimulationScreen("UTF-8")
+	if err := screen.Init(); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	screen.SetSize(width, height)
+	t.Cleanup(screen.Fini)
+	return screen
+}
+
+// screenLines returns the text shown on the screen, one string per line.
+func screenLines(screen tcell.SimulationScreen) []string {
+	cells, width, height := screen.GetContents()
+	lines := make([]string, 0, height)
+	for y := 0; y < height; y++ {
+		var line strings.Builder
+		for x := 0; x < width; x++ {
+			if runes := cells[y*width+x].Runes; len(runes) > 0 {
+				line.WriteString(string(runes))
+			} else {
+				line.WriteRune(' ')
+			}
+		}
+		lines = append(lines, line.String())
+	}
+	return lines
+}
+
+func newTestSession(t *testing.T, width, height int) *state.GameSession {
+	rules := state.DefaultRules()
+	rules.Width, rules.Height = width, height
+	session, err := state.NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	return session
+}
+
+func TestNewLayout_Centered(t *testing.T) {
+	screen := newTestScreen(t, 100, 40)
+	board := state.NewBoard(4, 4)
+	layout, fits := newLayout(screen, theme.Bundled()[0], board, nil, 0)
+	if !fits {
+		t.Fatal("Expected board to fit")
+	}
+	if layout.cellHeight == 1 {
+		t.Error("Expected regular layout on a big screen")
+	}
+
+	left, top := layout.x, layout.y
+	right := 100 - (layout.x + layout.boardWidth(board))
+	bottom := 40 - (layout.y + layout.boardHeight(board))
+	//Odd amounts of space can't be split evenly.
+	if abs(left-right) > 1 || abs(top-bottom) > 1 {
+		t.Errorf("Expected board to be centered, got space left %d, right %d, top %d, bottom %d",
+			left, right, top, bottom)
+	}
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func TestNewLayout_Compact(t *testing.T) {
+	//The smallest regular 4x4 board is 30x15.
+	screen := newTestScreen(t, 30, 10)
+	layout, fits := newLayout(screen, theme.Bundled()[0], state.NewBoard(4, 4), nil, 0)
+	if !fits {
+		t.Fatal("Expected compact board to fit")
+	}
+	if layout.cellWidth != compactCellWidth || layout.cellHeight != 1 || layout.gapY != 0 {
+		t.Errorf("Expected compact layout, got cells of %dx%d", layout.cellWidth, layout.cellHeight)
+	}
+}
+
+func TestDrawGameBoard_Compact(t *testing.T) {
+	screen := newTestScreen(t, 40, 10)
+	session := newTestSession(t, 4, 4)
+	newRenderer(theme.Bundled(), 0).drawGameBoard(screen, session, 0, 0)
+
+	text := strings.Join(screenLines(screen), "\n")
+	if strings.Contains(text, "Terminal too small") {
+		t.Fatalf("Expected compact board to fit:\n%s", text)
+	}
+	for _, row := range session.GameBoard {
+		for _, cell := range row {
+			if cell != 0 && !strings.Contains(text, fmt.Sprint(cell)) {
+				t.Errorf("Expected tile %d to be shown:\n%s", cell, text)
+			}
+		}
+	}
+}
+
+func TestDrawGameBoard_TooSmall(t *testing.T) {
+	screen := newTestScreen(t, 20, 5)
+	session := newTestSession(t, 4, 4)
+	renderer := newRenderer(theme.Bundled(), 0)
+	renderer.drawGameBoard(screen, session, 0, 0)
+
+	layout, fits := newLayout(screen, renderer.theme(), session.GameBoard, renderer.panel(session, 0, 0), 0)
+	if fits {
+		t.Fatal("Expected board not to fit")
+	}
+	text := strings.Join(screenLines(screen), "\n")
+	need := fmt.Sprintf("Need %dx%d, have 20x5", layout.width, layout.height)
+	if !strings.Contains(text, "Terminal too small") || !strings.Contains(text, need) {
+		t.Errorf("Expected %q to be shown:\n%s", need, text)
+	}
+}
+
+func TestDrawGameBoard_PanelAbove(t *testing.T) {
+	//A compact 5x5 board leaves no room for the panel next to it.
+	screen := newTestScreen(t, 40, 12)
+	session := newTestSession(t, 5, 5)
+	renderer := newRenderer(theme.Bundled(), 0)
+	renderer.drawGameBoard(screen, session, 1234, 0)
+
+	layout, fits := newLayout(screen, renderer.theme(), session.GameBoard, renderer.panel(session, 1234, 0), 0)
+	if !fits || !layout.panelAbove {
+		t.Fatal("Expected the panel to be shown above the board")
+	}
+	if layout.width > 40 {
+		t.Errorf("Expected layout to fit 40 columns, got %d", layout.width)
+	}
+
+	lines := screenLines(screen)
+	text := strings.Join(lines, "\n")
+	for _, entry := range renderer.panel(session, 1234, 0) {
+		found := false
+		for _, line := range lines[:layout.y] {
+			found = found || strings.Contains(line, entry)
+		}
+		if !found {
+			t.Errorf("Expected %q to be shown above the board:\n%s", entry, text)
+		}
+	}
+}
+
+func TestDrawGameBoard_CompactBorders(t *testing.T) {
+	mono, _ := theme.Find("mono")
+	screen := newTestScreen(t, 40, 10)
+	rules := state.DefaultRules()
+	rules.InitialTiles = []state.Tile{
+		{Position: state.Position{Row: 0, Column: 0}, Value: 2},
+		{Position: state.Position{Row: 1, Column: 1}, Value: 2048},
+	}
+	session, err := state.NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	newRenderer([]*theme.Theme{mono}, 0).drawGameBoard(screen, session, 0, 0)
+
+	text := strings.Join(screenLines(screen), "\n")
+	for _, tile := range []string{"[ 2   ]", "[2048 ]"} {
+		if !strings.Contains(text, tile) {
+			t.Errorf("Expected %q in compact mono layout:\n%s", tile, text)
+		}
+	}
+}
@@ -1,17 +1,143 @@
 package main
 
 import (
-	"math/rand"
+	"flag"
+	"fmt"
 	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/engine"
 	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/storage"
+	"github.com/Bios-Marcel/2048-terminal/theme"
 	"github.com/gdamore/tcell/v2"
 )
 
 func main() {
-	//Otherwise we'll always start on the same tile.
-	rand.Seed(time.Now().Unix())
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "scores":
+			os.Exit(runScores(os.Args[2:]))
+		case "replay":
+			os.Exit(runReplay(os.Args[2:]))
+		case "verify":
+			os.Exit(runVerify(os.Args[2:]))
+		case "simulate":
+			os.Exit(runSimulate(os.Args[2:]))
+		case "bot":
+			os.Exit(runBot(os.Args[2:]))
+		case "serve":
+			os.Exit(runServe(os.Args[2:]))
+		}
+	}
+
+	size := flag.String("size", "4", "size of the board, either N for a square board or WIDTHxHEIGHT")
+	spawn := flag.String("spawn", "2:0.9,4:0.1", "weighted tile values to spawn, in the form VALUE:WEIGHT,...")
+	startTiles := flag.Int("start-tiles", 2, "amount of randomly placed tiles on a new board")
+	customStart := flag.String("custom-start", "", "explicit start tiles in the form ROW:COLUMN:VALUE,..., starting at 0:0 in the top left")
+	target := flag.Uint("target", 2048, "tile that wins the game, 0 for endless play")
+	undoLimit := flag.Int("undo", 10, "amount of moves that can be undone in a row, 0 disables undo, -1 is unlimited")
+	fixedSeed := flag.Int64("seed", 0, "seed for spawning tiles; games with the same seed and moves are identical (default random)")
+	flag.Usage = func() {
+		output := flag.CommandLine.Output()
+		fmt.Fprintln(output, "Usage: 2048-terminal [flags]")
+		fmt.Fprintln(output, "       2048-terminal <command> [arguments]")
+		fmt.Fprintln(output, "\nCommands:")
+		fmt.Fprintln(output, "  scores\tprint the high score table")
+		fmt.Fprintln(output, "  replay\tplay back a replay recorded via --record")
+		fmt.Fprintln(output, "  verify\tcheck that a replay and its result are genuine")
+		fmt.Fprintln(output, "  simulate\tplay many games with a strategy and print statistics")
+		fmt.Fprintln(output, "  bot\t\tlet an engine play games without a terminal UI")
+		fmt.Fprintln(output, "  serve\t\tserve an HTTP/JSON API for playing games")
+		fmt.Fprintln(output, "\nFlags:")
+		flag.PrintDefaults()
+	}
+	loadPath := flag.String("load", "", "resume the game saved in the given file")
+	savePath := flag.String("save", "", "file to save the game to when quitting (default autosave in the data directory)")
+	recordDirectory := flag.String("record", "", "directory to record a replay of every game into")
+	autoplay := flag.String("autoplay", "", "let a strategy play the game: "+strings.Join(ai.StrategyNames, ", "))
+	autoplaySpeed := flag.Float64("autoplay-speed", 4, "initial speed of --autoplay and --bot in moves per second")
+	botCommand := flag.String("bot", "", "let an engine play the game, given as command line separated by spaces")
+	botTimeLimit := flag.Duration("bot-time-limit", time.Second, "time the --bot engine has for each move")
+	animations := flag.Bool("animations", true, "animate sliding, merging and spawning tiles")
+	themeChoice := flag.String("theme", "", "theme to start with, either one of "+strings.Join(theme.Names(), ", ")+" or the path of a JSON theme file (default depends on the terminal's colors)")
+	flag.Parse()
+
+	rules := state.DefaultRules()
+	var sizeError error
+	rules.Width, rules.Height, sizeError = parseSize(*size)
+	if sizeError != nil {
+		fmt.Fprintf(os.Stderr, "invalid --size: %s\n", sizeError)
+		os.Exit(2)
+	}
+	var spawnError error
+	rules.SpawnPolicy, spawnError = parseSpawnPolicy(*spawn)
+	if spawnError != nil {
+		fmt.Fprintf(os.Stderr, "invalid --spawn: %s\n", spawnError)
+		os.Exit(2)
+	}
+	rules.StartTiles = *startTiles
+	rules.TargetTile = *target
+	rules.UndoLimit = *undoLimit
+	if *customStart != "" {
+		var customStartError error
+		rules.InitialTiles, customStartError = parseTiles(*customStart)
+		if customStartError != nil {
+			fmt.Fprintf(os.Stderr, "invalid --custom-start: %s\n", customStartError)
+			os.Exit(2)
+		}
+	}
+	if rulesError := rules.Validate(); rulesError != nil {
+		fmt.Fprintln(os.Stderr, rulesError)
+		os.Exit(2)
+	}
+	if *autoplay != "" && *botCommand != "" {
+		fmt.Fprintln(os.Stderr, "--autoplay and --bot can't be combined")
+		os.Exit(2)
+	}
+
+	//Without a fixed seed, every game, including restarts, is different.
+	//With a fixed seed, restarting replays the same game.
+	seedIsFixed := isFlagSet(flag.CommandLine, "seed")
+	nextSeed := func() int64 {
+		if seedIsFixed {
+			return *fixedSeed
+		}
+		return state.RandomSeed()
+	}
+
+	renderNotificationChannel := make(chan bool)
+
+	//Games are saved on quit, either into the explicitly chosen file, or
+	//into the autosave, which is offered for resuming on the next launch.
+	autosavePath, autosavePathError := storage.AutosavePath()
+	saveTarget := *savePath
+	if saveTarget == "" && autosavePathError == nil {
+		saveTarget = autosavePath
+	}
+
+	var gameSession, resumableSession *state.GameSession
+	if *loadPath != "" {
+		var loadError error
+		gameSession, loadError = storage.LoadSession(*loadPath, renderNotificationChannel)
+		if loadError != nil {
+			fmt.Fprintln(os.Stderr, loadError)
+			os.Exit(1)
+		}
+	} else if autosavePathError == nil {
+		if _, statError := os.Stat(autosavePath); statError == nil {
+			var loadError error
+			resumableSession, loadError = storage.LoadSession(autosavePath, renderNotificationChannel)
+			if loadError != nil {
+				fmt.Fprintf(os.Stderr, "Ignoring previous game: %s\n", loadError)
+			}
+		}
+	}
 
 	screen, screenCreationError := createScreen()
 	if screenCreationError != nil {
@@ -21,24 +147,141 @@ func main() {
 	//Cleans up the terminal buffer and returns it to the shell.
 	defer screen.Fini()
 
+	themes, themeIndex, themeError := loadThemes(*themeChoice, detectColorSupport(screen))
+	if themeError != nil {
+		screen.Fini()
+		fmt.Fprintf(os.Stderr, "invalid --theme: %s\n", themeError)
+		os.Exit(2)
+	}
+
 	//renderer used for drawing the board and the menu.
-	renderer := newRenderer()
+	renderer := newRenderer(themes, themeIndex)
+	keeper := newScoreKeeper()
 
-	renderNotificationChannel := make(chan bool)
-	gameSession := state.NewGameSession(renderNotificationChannel)
+	if resumableSession != nil && askYesNo(screen, "Resume previous game? (y/n)") {
+		gameSession = resumableSession
+	}
+	if gameSession != nil {
+		//Restarting should keep playing by the same rules.
+		rules = gameSession.Rules()
+	} else {
+		var sessionError error
+		gameSession, sessionError = state.NewGameSession(renderNotificationChannel, rules, nextSeed())
+		if sessionError != nil {
+			screen.Fini()
+			fmt.Fprintln(os.Stderr, sessionError)
+			os.Exit(2)
+		}
+	}
 
 	//Gameloop; We draw whenever there's a frame-change. This means we
 	//don't have any specific frame-rates and it could technically happen
 	//that we don't draw for a while. The first frame is drawn without
 	//waiting for a change, so that the screen doesn't stay empty.
 
+	//gameSession is only replaced by the input goroutine, on restarts.
+	//Other goroutines have to use currentSession.
+	var sessionMutex sync.Mutex
+	currentSession := func() *state.GameSession {
+		sessionMutex.Lock()
+		defer sessionMutex.Unlock()
+		return gameSession
+	}
+
+	//Reported on exit, as there's no good way of showing it during the game.
+	var replayError error
+	//play requires the lock of the session to be held.
+	play := func(direction state.Direction) {
+		session := currentSession()
+		result := session.Move(direction)
+		renderer.showMove(result, session.GameBoard, *animations)
+		if result.GameOver {
+			keeper.record(session)
+			if err := writeReplay(*recordDirectory, session); err != nil {
+				replayError = err
+			}
+		}
+	}
+
+	var autoplayer *autoplayer
+	var botEngine *engine.Engine
+	if *autoplay != "" {
+		//The rules might have changed by resuming a game.
+		strategy, strategyError := ai.NewStrategy(*autoplay, rules, state.RandomSeed())
+		if strategyError != nil {
+			screen.Fini()
+			fmt.Fprintf(os.Stderr, "invalid --autoplay: %s\n", strategyError)
+			os.Exit(2)
+		}
+		//Games the computer played are marked in the high score table.
+		autoplayer = newAutoplayer(*autoplay, strategy, *autoplaySpeed, currentSession,
+			func(direction state.Direction) {
+				keeper.playedBy(*autoplay)
+				play(direction)
+			})
+		go autoplayer.run()
+	} else if *botCommand != "" {
+		//The engine's stderr would mess up the terminal UI.
+		var engineError error
+		botEngine, engineError = engine.Start(strings.Fields(*botCommand), rules, nil, engineStartTimeout)
+		if engineError != nil {
+			screen.Fini()
+			fmt.Fprintf(os.Stderr, "invalid --bot: %s\n", engineError)
+			os.Exit(1)
+		}
+		name, player := botEngine.Name, "bot "+botEngine.Name
+		if name == "" {
+			name, player = "bot", "bot"
+		}
+		//Unlike strategies, bots can't be told apart by their name alone.
+		autoplayer = newAutoplayer(name, &botStrategy{engine: botEngine, timeLimit: *botTimeLimit}, *autoplaySpeed, currentSession,
+			func(direction state.Direction) {
+				keeper.playedBy(player)
+				play(direction)
+			})
+		go autoplayer.run()
+	}
+
+	move := func(direction state.Direction) {
+		if autoplayer != nil {
+			autoplayer.takeOver()
+		}
+		gameSession.Mutex.Lock()
+		play(direction)
+		gameSession.Mutex.Unlock()
+	}
+
 	//Listen for key input on the gameboard.
 	go func() {
 		for {
 			switch event := screen.PollEvent().(type) {
 			case *tcell.EventKey:
+				//Any key skips the running animation.
+				gameSession.Mutex.Lock()
+				renderer.animation = nil
+				gameSession.Mutex.Unlock()
+
 				if event.Key() == tcell.KeyCtrlC {
+					gameSession.Mutex.Lock()
+					saveError := saveOnQuit(gameSession, saveTarget, saveTarget == autosavePath)
 					screen.Fini()
+					if botEngine != nil {
+						botEngine.Close()
+					}
+					if saveError != nil {
+						fmt.Fprintln(os.Stderr, saveError)
+					}
+					if keeper.lastError != nil {
+						fmt.Fprintf(os.Stderr, "Error with high scores: %s\n", keeper.lastError)
+					}
+					if err := writeReplay(*recordDirectory, gameSession); err != nil {
+						replayError = err
+					}
+					if replayError != nil {
+						fmt.Fprintf(os.Stderr, "Error recording replay: %s\n", replayError)
+					}
+					//Allows replaying the game, for example for bug reports.
+					fmt.Printf("Seed: %d\n", gameSession.Seed())
 					os.Exit(0)
 				} else if event.Key() == tcell.KeyCtrlR {
 					//RESTART!
@@ -47,51 +290,230 @@ func main() {
 					//the next session.
 					oldGameSession := gameSession
 					oldGameSession.Mutex.Lock()
+					//Abandoned games count as well.
+					keeper.record(oldGameSession)
+					keeper.startGame()
+					if err := writeReplay(*recordDirectory, oldGameSession); err != nil {
+						replayError = err
+					}
 
 					//Make sure the state knows it's supposed to be dead.
 					oldGameSession.GameOver = true
 					screen.Clear()
-					gameSession = state.NewGameSession(renderNotificationChannel)
+					//The rules have already been validated on startup.
+					sessionMutex.Lock()
+					gameSession, _ = state.NewGameSession(renderNotificationChannel, rules, nextSeed())
+					sessionMutex.Unlock()
 					gameSession.Mutex.Lock()
+					renderer.reset()
 
 					oldGameSession.Mutex.Unlock()
 					gameSession.Mutex.Unlock()
 					renderNotificationChannel <- true
-				} else if event.Key() == tcell.KeyDown || eventIsRune(event, 's') {
+				} else if eventIsRune(event, 'c') {
 					gameSession.Mutex.Lock()
-					gameSession.Down()
+					gameSession.KeepPlaying()
 					gameSession.Mutex.Unlock()
-				} else if event.Key() == tcell.KeyUp || eventIsRune(event, 'w') {
+				} else if eventIsRune(event, 'h') {
 					gameSession.Mutex.Lock()
-					gameSession.Up()
+					//Asking again for the same board doesn't count twice.
+					if renderer.hint == nil || !reflect.DeepEqual(renderer.hint.board, gameSession.GameBoard) {
+						renderer.hint = computeHint(gameSession)
+					}
 					gameSession.Mutex.Unlock()
-				} else if event.Key() == tcell.KeyLeft || eventIsRune(event, 'a') {
+					renderNotificationChannel <- true
+				} else if eventIsRune(event, 't') {
 					gameSession.Mutex.Lock()
-					gameSession.Left()
+					renderer.nextTheme()
 					gameSession.Mutex.Unlock()
-				} else if event.Key() == tcell.KeyRight || eventIsRune(event, 'd') {
+					renderNotificationChannel <- true
+				} else if eventIsRune(event, 'u') {
+					if autoplayer != nil {
+						autoplayer.takeOver()
+					}
+					gameSession.Mutex.Lock()
+					wasOver := gameSession.GameOver
+					if gameSession.Undo() && wasOver && !gameSession.GameOver {
+						//The game goes on, so it has to be recorded again.
+						keeper.resume()
+					}
+					gameSession.Mutex.Unlock()
+				} else if eventIsRune(event, 'r') {
+					if autoplayer != nil {
+						autoplayer.takeOver()
+					}
 					gameSession.Mutex.Lock()
-					gameSession.Right()
+					gameSession.Redo()
 					gameSession.Mutex.Unlock()
+				} else if autoplayer != nil && eventIsRune(event, ' ') {
+					autoplayer.togglePause()
+					renderNotificationChannel <- true
+				} else if autoplayer != nil && eventIsRune(event, '.') {
+					autoplayer.stepOnce()
+					renderNotificationChannel <- true
+				} else if autoplayer != nil && (eventIsRune(event, '+') || eventIsRune(event, '-')) {
+					if eventIsRune(event, '+') {
+						autoplayer.changeSpeed(1)
+					} else {
+						autoplayer.changeSpeed(-1)
+					}
+					renderNotificationChannel <- true
+				} else if event.Key() == tcell.KeyDown || eventIsRune(event, 's') {
+					move(state.Down)
+				} else if event.Key() == tcell.KeyUp || eventIsRune(event, 'w') {
+					move(state.Up)
+				} else if event.Key() == tcell.KeyLeft || eventIsRune(event, 'a') {
+					move(state.Left)
+				} else if event.Key() == tcell.KeyRight || eventIsRune(event, 'd') {
+					move(state.Right)
 				}
 			case *tcell.EventResize:
+				//The layout is computed on each frame, so redrawing
+				//everything is enough.
 				gameSession.Mutex.Lock()
-				screen.Clear()
+				screen.Sync()
 				gameSession.Mutex.Unlock()
 				renderNotificationChannel <- true
-				//TODO Handle resize; Validate session;
 			default:
 				//Unsupported or irrelevant event
 			}
 		}
 	}()
 
+	//The elapsed time has to be updated, even if nothing happens.
+	clock := time.NewTicker(time.Second)
 	for {
 		//We start lock before draw in order to avoid drawing crap.
-		gameSession.Mutex.Lock()
-		renderer.drawGameBoard(screen, gameSession)
-		gameSession.Mutex.Unlock()
+		session := currentSession()
+		session.Mutex.Lock()
+		var statusLines []string
+		if autoplayer != nil {
+			statusLines = append(statusLines, autoplayer.status())
+		}
+		renderer.drawGameBoard(screen, session, keeper.best(session), keeper.elapsed(), statusLines...)
+		animating := renderer.animation != nil && renderer.animation.running(time.Now())
+		session.Mutex.Unlock()
+
+		//While animating, frames are drawn in fixed intervals, otherwise
+		//only on change and once per second for the clock.
+		if animating {
+			select {
+			case <-renderNotificationChannel:
+			case <-time.After(frameDuration):
+			}
+		} else {
+			select {
+			case <-renderNotificationChannel:
+			case <-clock.C:
+			}
+		}
+	}
+}
+
+// saveOnQuit writes the session to the given path. Finished games aren't
+// worth resuming, so instead of autosaving them, the autosave is removed.
+func saveOnQuit(session *state.GameSession, path string, isAutosave bool) error {
+	if path == "" {
+		return nil
+	}
+
+	if isAutosave && session.GameOver {
+		if removeError := os.Remove(path); removeError != nil && !os.IsNotExist(removeError) {
+			return removeError
+		}
+		return nil
+	}
+
+	return storage.SaveSession(path, session)
+}
 
-		<-renderNotificationChannel
+// parseSize parses board dimensions in the form of "N" (square) or
+// "WIDTHxHEIGHT", for example "4" or "4x6".
+func parseSize(value string) (int, int, error) {
+	parts := strings.Split(strings.ToLower(value), "x")
+	if len(parts) > 2 {
+		return 0, 0, fmt.Errorf("%q isn't of the form N or WIDTHxHEIGHT", value)
 	}
+
+	dimensions := make([]int, 0, 2)
+	for _, part := range parts {
+		dimension, parseError := strconv.Atoi(strings.TrimSpace(part))
+		if parseError != nil {
+			return 0, 0, fmt.Errorf("%q isn't of the form N or WIDTHxHEIGHT", value)
+		}
+		if dimension < state.MinSize || dimension > state.MaxSize {
+			return 0, 0, fmt.Errorf("each dimension has to be between %d and %d", state.MinSize, state.MaxSize)
+		}
+		dimensions = append(dimensions, dimension)
+	}
+
+	if len(dimensions) == 1 {
+		return dimensions[0], dimensions[0], nil
+	}
+	return dimensions[0], dimensions[1], nil
+}
+
+// parseSpawnPolicy parses a comma separated list of VALUE:WEIGHT pairs,
+// for example "2:0.9,4:0.1".
+func parseSpawnPolicy(value string) (state.SpawnPolicy, error) {
+	var policy state.SpawnPolicy
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%q isn't of the form VALUE:WEIGHT", entry)
+		}
+
+		tileValue, parseError := strconv.ParseUint(parts[0], 10, 32)
+		if parseError != nil {
+			return nil, fmt.Errorf("invalid tile value %q", parts[0])
+		}
+		weight, parseError := strconv.ParseFloat(parts[1], 64)
+		if parseError != nil {
+			return nil, fmt.Errorf("invalid weight %q", parts[1])
+		}
+
+		policy = append(policy, state.SpawnWeight{Value: uint(tileValue), Weight: weight})
+	}
+
+	if validationError := policy.Validate(); validationError != nil {
+		return nil, validationError
+	}
+	return policy, nil
+}
+
+// parseTiles parses a comma separated list of ROW:COLUMN:VALUE triples,
+// for example "0:0:2,3:3:1024".
+func parseTiles(value string) ([]state.Tile, error) {
+	var tiles []state.Tile
+	for _, entry := range strings.Split(value, ",") {
+		parts := strings.Split(strings.TrimSpace(entry), ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("%q isn't of the form ROW:COLUMN:VALUE", entry)
+		}
+
+		row, rowError := strconv.Atoi(parts[0])
+		column, columnError := strconv.Atoi(parts[1])
+		tileValue, valueError := strconv.ParseUint(parts[2], 10, 32)
+		if rowError != nil || columnError != nil || valueError != nil {
+			return nil, fmt.Errorf("%q isn't of the form ROW:COLUMN:VALUE", entry)
+		}
+
+		tiles = append(tiles, state.Tile{
+			Position: state.Position{Row: row, Column: column},
+			Value:    uint(tileValue),
+		})
+	}
+	return tiles, nil
+}
+
+// isFlagSet checks whether a flag has been explicitly passed, as opposed
+// to using its default value.
+func isFlagSet(flags *flag.FlagSet, name string) bool {
+	var set bool
+	flags.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			set = true
+		}
+	})
+	return set
 }
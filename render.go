@@ -2,73 +2,245 @@ package main
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/theme"
 	"github.com/gdamore/tcell/v2"
 )
 
+// renderer draws the game. Its fields must only be accessed with the lock
+// of the session held.
 type renderer struct {
+	// themes are the themes that can be switched between, with the
+	// current one at index themeIndex.
+	themes     []*theme.Theme
+	themeIndex int
+	// hint is drawn on top of the board, as long as the board doesn't
+	// change.
+	hint *hint
+	// animation is the animation of the last move, if it's still running.
+	animation *animation
+	// lastMove is the last move, whose score is shown as long as the board
+	// doesn't change.
+	lastMove *lastMove
 }
 
-func newRenderer() *renderer {
-	return nil
-}
-
-var (
-	cellWidth             = 6
-	cellHeight            = cellWidth / 2
-	defaultCellBackground = tcell.StyleDefault.Reverse(true)
-	styles                = map[uint]tcell.Style{
-		2:    tcell.StyleDefault.Background(tcell.Color100),
-		4:    tcell.StyleDefault.Background(tcell.Color101),
-		8:    tcell.StyleDefault.Background(tcell.Color102),
-		16:   tcell.StyleDefault.Background(tcell.Color103),
-		32:   tcell.StyleDefault.Background(tcell.Color105),
-		64:   tcell.StyleDefault.Background(tcell.Color106),
-		128:  tcell.StyleDefault.Background(tcell.Color108),
-		256:  tcell.StyleDefault.Background(tcell.Color109),
-		1024: tcell.StyleDefault.Background(tcell.Color110),
-		2048: tcell.StyleDefault.Background(tcell.Color111),
-		4096: tcell.StyleDefault.Background(tcell.Color112),
-		8192: tcell.StyleDefault.Background(tcell.Color113),
+type lastMove struct {
+	board       state.Board
+	scoreGained uint
+}
+
+// newRenderer creates a renderer that starts with the theme at the given
+// index.
+func newRenderer(themes []*theme.Theme, themeIndex int) *renderer {
+	return &renderer{themes: themes, themeIndex: themeIndex}
+}
+
+func (renderer *renderer) theme() *theme.Theme {
+	return renderer.themes[renderer.themeIndex]
+}
+
+// nextTheme switches to the next theme, starting over after the last.
+func (renderer *renderer) nextTheme() {
+	renderer.themeIndex = (renderer.themeIndex + 1) % len(renderer.themes)
+}
+
+// showMove has to be called after each move, so that its score and, if
+// desired, its animation can be shown.
+func (renderer *renderer) showMove(result state.MoveResult, board state.Board, animate bool) {
+	if !result.Changed {
+		return
 	}
-)
+	renderer.lastMove = &lastMove{board: board.Copy(), scoreGained: result.ScoreGained}
+	if animate {
+		renderer.animation = newAnimation(result, board)
+	}
+}
+
+// reset forgets everything about the previous game.
+func (renderer *renderer) reset() {
+	renderer.hint = nil
+	renderer.animation = nil
+	renderer.lastMove = nil
+}
+
+// drawGameBoard draws the board of the session, the panel with its
+// statistics and the given status lines.
+func (renderer *renderer) drawGameBoard(screen tcell.Screen, session *state.GameSession, bestScore uint, elapsed time.Duration, statusLines ...string) {
+	//Overlays such as the game over message might have to disappear, for
+	//example after undoing a move. Since tcell only draws what changed,
+	//this doesn't cause flickering.
+	screen.Clear()
+	currentTheme := renderer.theme()
+	screen.Fill(' ', currentTheme.Background)
 
-func (renderer *renderer) drawGameBoard(screen tcell.Screen, session *state.GameSession) {
-	for rowIndex, row := range session.GameBoard {
+	showHint := renderer.hint != nil && reflect.DeepEqual(renderer.hint.board, session.GameBoard)
+	if showHint {
+		statusLines = append(statusLines, renderer.hint.description())
+	}
+	var footerLines int
+	if len(statusLines) > 0 {
+		//A line of space separates the status lines from the board.
+		footerLines = len(statusLines) + 1
+	}
+	panel := renderer.panel(session, bestScore, elapsed)
+	layout, fits := newLayout(screen, currentTheme, session.GameBoard, panel, footerLines)
+	if !fits {
+		drawTooSmall(screen, layout)
+		screen.Show()
+		return
+	}
+
+	if renderer.animation != nil && reflect.DeepEqual(renderer.animation.board, session.GameBoard) {
+		renderer.animation.draw(screen, layout, time.Now())
+	} else {
+		layout.drawBoard(screen, session.GameBoard)
+	}
+	if showHint {
+		layout.drawHintArrows(screen, session.GameBoard, renderer.hint.evaluations[0].Direction)
+	}
+
+	layout.drawPanel(screen, panel...)
+	layout.drawFooter(screen, session.GameBoard, statusLines...)
+
+	if session.AwaitingDecision() {
+		layout.drawMessageBox(screen, session.GameBoard,
+			fmt.Sprintf("You win! Score: %d", session.Score()),
+			"C: Keep playing",
+			"Ctrl+R: New game")
+	} else if session.GameOver {
+		layout.drawMessageBox(screen, session.GameBoard, fmt.Sprintf("Game Over; Score: %d", session.Score()))
+	}
+
+	screen.Show()
+}
+
+// panel returns the statistics of the game, one per line.
+func (renderer *renderer) panel(session *state.GameSession, bestScore uint, elapsed time.Duration) []string {
+	score := fmt.Sprintf("Score: %d", session.Score())
+	if renderer.lastMove != nil && renderer.lastMove.scoreGained > 0 &&
+		reflect.DeepEqual(renderer.lastMove.board, session.GameBoard) {
+		score += fmt.Sprintf(" +%d", renderer.lastMove.scoreGained)
+	}
+	return []string{
+		score,
+		fmt.Sprintf("Best: %d", bestScore),
+		fmt.Sprintf("Moves: %d", session.MoveCount()),
+		"Time: " + formatElapsed(elapsed),
+		fmt.Sprintf("Max tile: %d", session.GameBoard.MaxTile()),
+		"Theme: " + renderer.theme().Name,
+	}
+}
+
+// formatElapsed formats the duration as minutes and seconds, with hours
+// only added if necessary.
+func formatElapsed(elapsed time.Duration) string {
+	seconds := int(elapsed / time.Second)
+	if seconds >= 3600 {
+		return fmt.Sprintf("%d:%02d:%02d", seconds/3600, seconds/60%60, seconds%60)
+	}
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// drawBoard draws all cells of the board and the gaps between them.
+func (layout layout) drawBoard(screen tcell.Screen, board state.Board) {
+	layout.drawGaps(screen, board)
+	for rowIndex, row := range board {
 		for cellIndex, cell := range row {
-			startX := cellIndex*cellWidth + cellIndex*2
-			startY := rowIndex*cellHeight + rowIndex
+			x, y := layout.cellPosition(state.Position{Row: rowIndex, Column: cellIndex})
+			layout.drawTile(screen, x, y, layout.cellWidth, layout.cellHeight, cell)
+		}
+	}
+}
 
-			style, avail := styles[cell]
-			if !avail {
-				style = defaultCellBackground
-			}
+// drawGaps fills the area of the board, so that the gaps between the
+// cells are visible once the cells have been drawn.
+func (layout layout) drawGaps(screen tcell.Screen, board state.Board) {
+	drawRectangle(screen, layout.x, layout.y, layout.boardWidth(board), layout.boardHeight(board), layout.theme.Gap)
+}
 
-			drawRectangle(screen, startX, startY, cellWidth, cellHeight, style)
+// drawTile draws a tile of the given size, with its value centered. The
+// value is left out if it doesn't fit, which happens during animations.
+// If the theme asks for borders, tiles that are high enough get one, while
+// lower tiles get brackets around their value, if there's room for them.
+func (layout layout) drawTile(screen tcell.Screen, x, y, width, height int, value uint) {
+	style := layout.theme.Tile(value)
+	drawRectangle(screen, x, y, width, height, style)
+	borders := layout.theme.Borders && value != 0
+	if borders && width >= 3 && height >= 3 {
+		drawBorder(screen, x, y, width, height, style)
+	}
 
-			runes := []rune(strconv.FormatUint(uint64(cell), 10))
-			xOffset := (cellWidth/2 - 1) - (len(runes)-1)/2
-			for index, r := range runes {
-				screen.SetContent(startX+xOffset+index, startY+(cellHeight-1)/2, r, nil, style)
+	runes := []rune(strconv.FormatUint(uint64(value), 10))
+	if len(runes) > width {
+		return
+	}
+	xOffset := (width/2 - 1) - (len(runes)-1)/2
+	if xOffset < 0 {
+		xOffset = 0
+	}
+	textY := y + (height-1)/2
+	for index, r := range runes {
+		screen.SetContent(x+xOffset+index, textY, r, nil, style)
+	}
+	if borders && height < 3 && xOffset >= 1 && xOffset+len(runes) < width-1 {
+		screen.SetContent(x, textY, '[', nil, style)
+		screen.SetContent(x+width-1, textY, ']', nil, style)
+	}
+}
+
+var hintArrows = map[state.Direction]rune{
+	state.Up:    '↑',
+	state.Down:  '↓',
+	state.Left:  '←',
+	state.Right: '→',
+}
+
+// drawHintArrows draws arrows pointing in the given direction into the
+// gaps between the cells, so that no tile is hidden. The compact layout
+// has no gaps between rows, so there are no arrows pointing up or down.
+func (layout layout) drawHintArrows(screen tcell.Screen, board state.Board, direction state.Direction) {
+	style := layout.theme.Hint
+	arrow := hintArrows[direction]
+	if direction == state.Left || direction == state.Right {
+		for rowIndex := range board {
+			for gapIndex := 1; gapIndex < board.Width(); gapIndex++ {
+				x, y := layout.cellPosition(state.Position{Row: rowIndex, Column: gapIndex})
+				screen.SetContent(x-layout.gapX, y+(layout.cellHeight-1)/2, arrow, nil, style)
 			}
 		}
+		return
 	}
 
-	if session.GameOver {
-		boardWidth := cellWidth*len(session.GameBoard) + len(session.GameBoard) - 1
-		boardHeight := cellHeight*len(session.GameBoard) + len(session.GameBoard) - 1
-		gameOverBoxHeight := 3
-		text := fmt.Sprintf("Game Over; Score: %d", session.Score())
-		startX := boardWidth/2 - len(text)/2 + 1
-		startY := boardHeight/2 - gameOverBoxHeight/2
-		drawRectangle(screen, startX, startY, len(text)+2, gameOverBoxHeight, tcell.StyleDefault)
-		startX = startX + 1
-		for index, r := range text {
-			screen.SetContent(startX+index, startY+(cellHeight-1)/2, r, nil, tcell.StyleDefault)
+	if layout.gapY == 0 {
+		return
+	}
+	for cellIndex := 0; cellIndex < board.Width(); cellIndex++ {
+		for gapIndex := 1; gapIndex < board.Height(); gapIndex++ {
+			x, y := layout.cellPosition(state.Position{Row: gapIndex, Column: cellIndex})
+			screen.SetContent(x+(layout.cellWidth/2-1), y-layout.gapY, arrow, nil, style)
 		}
 	}
+}
 
-	screen.Show()
+// drawMessageBox draws the given lines centered on top of the board,
+// surrounded by a one cell wide padding.
+func (layout layout) drawMessageBox(screen tcell.Screen, board state.Board, lines ...string) {
+	var textWidth int
+	for _, line := range lines {
+		if len(line) > textWidth {
+			textWidth = len(line)
+		}
+	}
+
+	boxHeight := len(lines) + 2
+	startX := layout.x + layout.boardWidth(board)/2 - textWidth/2 - 1
+	startY := layout.y + layout.boardHeight(board)/2 - boxHeight/2
+	drawRectangle(screen, startX, startY, textWidth+2, boxHeight, layout.theme.Overlay)
+	for lineIndex, line := range lines {
+		drawText(screen, startX+1, startY+1+lineIndex, layout.theme.Overlay, line)
+	}
 }
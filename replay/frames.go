@@ -0,0 +1,66 @@
+package replay
+
+import (
+	"fmt"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Frame is the state of the game after a certain move.
+type Frame struct {
+	Board state.Board
+	Score uint
+	// Move is the move that led to this frame. It is nil for the first
+	// frame, which shows the start tiles.
+	Move *state.LoggedMove
+	// Result describes the move that led to this frame.
+	Result state.MoveResult
+}
+
+// Frames plays back the replay using its recorded spawns. The first frame
+// shows the start tiles, followed by one frame per move. An error is
+// returned if a move is impossible or a spawn doesn't fit the board.
+func (replay *Replay) Frames() ([]Frame, error) {
+	board := state.NewBoard(replay.Rules.Width, replay.Rules.Height)
+	for _, tile := range replay.Start {
+		if err := checkSpawn(board, tile); err != nil {
+			return nil, fmt.Errorf("start tile %s: %w", formatTile(tile), err)
+		}
+		board = board.Spawn(tile.Position, tile.Value)
+	}
+
+	frames := make([]Frame, 0, len(replay.Moves)+1)
+	frames = append(frames, Frame{Board: board})
+	var score uint
+	for index := range replay.Moves {
+		move := &replay.Moves[index]
+		moved, result := board.Move(move.Direction)
+		if !result.Changed {
+			return nil, fmt.Errorf("move %d (%s) doesn't change the board", index+1, move.Direction)
+		}
+
+		if move.Spawn != nil {
+			if err := checkSpawn(moved, *move.Spawn); err != nil {
+				return nil, fmt.Errorf("move %d (%s): %w", index+1, move.Direction, err)
+			}
+			moved = moved.Spawn(move.Spawn.Position, move.Spawn.Value)
+			result.Spawn = move.Spawn
+		}
+
+		board = moved
+		score += result.ScoreGained
+		result.GameOver = board.IsTerminal()
+		frames = append(frames, Frame{Board: board, Score: score, Move: move, Result: result})
+	}
+	return frames, nil
+}
+
+func checkSpawn(board state.Board, tile state.Tile) error {
+	if tile.Row < 0 || tile.Row >= board.Height() || tile.Column < 0 || tile.Column >= board.Width() {
+		return fmt.Errorf("spawn %s is outside of the board", formatTile(tile))
+	}
+	if board[tile.Row][tile.Column] != 0 {
+		return fmt.Errorf("spawn %s is on an occupied cell", formatTile(tile))
+	}
+	return nil
+}
@@ -0,0 +1,283 @@
+// Package replay records games and plays them back.
+//
+// Replays are stored in a line-based text format. Each line starts with a
+// keyword, followed by space separated arguments. Empty lines and lines
+// starting with '#' are ignored. The lines have to appear in this order:
+//
+//	2048-replay 1
+//	seed -4711
+//	rules {"width":4,"height":4,...}
+//	start 0:1:2 3:3:2
+//	move left 2:3:2
+//	move up 0:0:4
+//	result 8 4 4,0,0,0/0,0,0,0/0,0,0,0/2,0,0,0
+//
+// The first line identifies the format and its version. "seed" is the seed
+// of the game's random source and "rules" the JSON encoded state.Rules.
+// "start" lists the tiles on the board before the first move, each in the
+// form ROW:COLUMN:VALUE, with 0:0 being the top left cell. Each "move" is a
+// move that changed the board, given by its direction (up, down, left or
+// right) and the tile spawned afterwards. If no tile has been spawned,
+// which can only happen on the last move, the spawn is omitted.
+//
+// The optional "result" line has to be the last line. It claims the score,
+// the highest tile and the board at the end of the game. The board is
+// given row by row, separated by '/', with the cells of a row separated by
+// ','. Empty cells are 0.
+//
+// The spawns are recorded explicitly, so a replay can be played back
+// without depending on the random source. The seed allows verifying that
+// the spawns haven't been tampered with.
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Version is the version of the replay format written by this package.
+const Version = 1
+
+const header = "2048-replay"
+
+// Replay is a complete game, from the start tiles to the last move.
+type Replay struct {
+	Seed  int64
+	Rules state.Rules
+	Start []state.Tile
+	Moves []state.LoggedMove
+	// Result is the claimed outcome of the game. It's nil if the replay
+	// doesn't make any claims.
+	Result *Result
+}
+
+// Result is the outcome of a game, as claimed by a replay.
+type Result struct {
+	Score   uint
+	MaxTile uint
+	Board   state.Board
+}
+
+// FromSession produces a replay of all moves played in the session so far.
+func FromSession(session *state.GameSession) *Replay {
+	return &Replay{
+		Seed:  session.Seed(),
+		Rules: session.Rules(),
+		Start: session.StartTiles(),
+		Moves: session.Log(),
+		Result: &Result{
+			Score:   session.Score(),
+			MaxTile: session.GameBoard.MaxTile(),
+			Board:   session.GameBoard.Copy(),
+		},
+	}
+}
+
+// Write encodes the replay in the replay format.
+func (replay *Replay) Write(writer io.Writer) error {
+	rules, err := json.Marshal(replay.Rules)
+	if err != nil {
+		return err
+	}
+
+	buffered := bufio.NewWriter(writer)
+	fmt.Fprintf(buffered, "%s %d\n", header, Version)
+	fmt.Fprintf(buffered, "seed %d\n", replay.Seed)
+	fmt.Fprintf(buffered, "rules %s\n", rules)
+
+	buffered.WriteString("start")
+	for _, tile := range replay.Start {
+		buffered.WriteString(" " + formatTile(tile))
+	}
+	buffered.WriteString("\n")
+
+	for _, move := range replay.Moves {
+		buffered.WriteString("move " + move.Direction.String())
+		if move.Spawn != nil {
+			buffered.WriteString(" " + formatTile(*move.Spawn))
+		}
+		buffered.WriteString("\n")
+	}
+
+	if replay.Result != nil {
+		fmt.Fprintf(buffered, "result %d %d %s\n", replay.Result.Score, replay.Result.MaxTile, replay.Result.Board.Format())
+	}
+	return buffered.Flush()
+}
+
+// WriteFile writes the replay to the given file, replacing it if it
+// already exists.
+func (replay *Replay) WriteFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := replay.Write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// Read decodes a replay written by Write. Errors name the offending line.
+func Read(reader io.Reader) (*Replay, error) {
+	replay := &Replay{}
+	scanner := bufio.NewScanner(reader)
+	//Rules can get long, for example with a custom start.
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	//expected is the keyword of the next mandatory line.
+	expected := header
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		if replay.Result != nil {
+			return nil, fmt.Errorf("line %d: the result has to be the last line", lineNumber)
+		}
+
+		keyword, arguments := line, ""
+		if index := strings.IndexByte(line, ' '); index != -1 {
+			keyword, arguments = line[:index], strings.TrimSpace(line[index+1:])
+		}
+		if expected != "" && keyword != expected {
+			return nil, fmt.Errorf("line %d: expected %q, but got %q", lineNumber, expected, keyword)
+		}
+
+		var err error
+		switch keyword {
+		case header:
+			if arguments != strconv.Itoa(Version) {
+				err = fmt.Errorf("unsupported version %q, expected %d", arguments, Version)
+			}
+			expected = "seed"
+		case "seed":
+			replay.Seed, err = strconv.ParseInt(arguments, 10, 64)
+			expected = "rules"
+		case "rules":
+			err = json.Unmarshal([]byte(arguments), &replay.Rules)
+			if err == nil {
+				err = replay.Rules.Validate()
+			}
+			expected = "start"
+		case "start":
+			for _, field := range strings.Fields(arguments) {
+				var tile state.Tile
+				if tile, err = parseTile(field); err != nil {
+					break
+				}
+				replay.Start = append(replay.Start, tile)
+			}
+			expected = ""
+		case "move":
+			var move state.LoggedMove
+			move, err = parseMove(arguments)
+			replay.Moves = append(replay.Moves, move)
+		case "result":
+			replay.Result, err = parseResult(arguments)
+		default:
+			err = fmt.Errorf("unknown keyword %q", keyword)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if expected != "" {
+		return nil, fmt.Errorf("incomplete replay, %q is missing", expected)
+	}
+
+	return replay, nil
+}
+
+// ReadFile reads the replay stored in the given file.
+func ReadFile(path string) (*Replay, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	replay, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("invalid replay %s: %w", path, err)
+	}
+	return replay, nil
+}
+
+func formatTile(tile state.Tile) string {
+	return fmt.Sprintf("%d:%d:%d", tile.Row, tile.Column, tile.Value)
+}
+
+func parseTile(value string) (state.Tile, error) {
+	parts := strings.Split(value, ":")
+	if len(parts) != 3 {
+		return state.Tile{}, fmt.Errorf("%q isn't of the form ROW:COLUMN:VALUE", value)
+	}
+
+	row, rowErr := strconv.Atoi(parts[0])
+	column, columnErr := strconv.Atoi(parts[1])
+	tileValue, valueErr := strconv.ParseUint(parts[2], 10, strconv.IntSize)
+	if rowErr != nil || columnErr != nil || valueErr != nil {
+		return state.Tile{}, fmt.Errorf("%q isn't of the form ROW:COLUMN:VALUE", value)
+	}
+
+	return state.Tile{
+		Position: state.Position{Row: row, Column: column},
+		Value:    uint(tileValue),
+	}, nil
+}
+
+func parseResult(arguments string) (*Result, error) {
+	fields := strings.Fields(arguments)
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("%q isn't of the form SCORE MAXTILE BOARD", arguments)
+	}
+
+	//Scores and tiles are as large as a uint, like on state.Board.
+	score, scoreErr := strconv.ParseUint(fields[0], 10, strconv.IntSize)
+	maxTile, maxTileErr := strconv.ParseUint(fields[1], 10, strconv.IntSize)
+	if scoreErr != nil || maxTileErr != nil {
+		return nil, fmt.Errorf("%q isn't of the form SCORE MAXTILE BOARD", arguments)
+	}
+	board, err := state.ParseBoard(fields[2])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Score: uint(score), MaxTile: uint(maxTile), Board: board}, nil
+}
+
+func parseMove(arguments string) (state.LoggedMove, error) {
+	fields := strings.Fields(arguments)
+	if len(fields) < 1 || len(fields) > 2 {
+		return state.LoggedMove{}, fmt.Errorf("%q isn't of the form DIRECTION [ROW:COLUMN:VALUE]", arguments)
+	}
+
+	direction, err := state.ParseDirection(fields[0])
+	if err != nil {
+		return state.LoggedMove{}, err
+	}
+	move := state.LoggedMove{Direction: direction}
+	if len(fields) == 2 {
+		spawn, err := parseTile(fields[1])
+		if err != nil {
+			return state.LoggedMove{}, err
+		}
+		move.Spawn = &spawn
+	}
+	return move, nil
+}
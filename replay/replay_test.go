@@ -0,0 +1,152 @@
+package replay
+
+import (
+	"bytes"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func playSession(t *testing.T, moves int) *state.GameSession {
+	t.Helper()
+	session, err := state.NewGameSession(nil, state.DefaultRules(), 21)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for index := 0; index < moves; index++ {
+		session.Move(state.Directions[index%len(state.Directions)])
+	}
+	return session
+}
+
+func TestReplay_WriteAndRead(t *testing.T) {
+	session := playSession(t, 50)
+	original := FromSession(session)
+
+	var buffer bytes.Buffer
+	if err := original.Write(&buffer); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	read, err := Read(&buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(original, read) {
+		t.Fatalf("Replay changed after writing and reading:\n%+v\n%+v", original, read)
+	}
+
+	frames, err := read.Frames()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(frames) != session.MoveCount()+1 {
+		t.Fatalf("Expected %d frames, got %d", session.MoveCount()+1, len(frames))
+	}
+	last := frames[len(frames)-1]
+	if !reflect.DeepEqual(last.Board, session.GameBoard) || last.Score != session.Score() {
+		t.Fatalf("Last frame doesn't match session")
+	}
+}
+
+func TestReplay_LargeTiles(t *testing.T) {
+	//Shifting by a variable, so that this compiles on 32 bit platforms.
+	shift := strconv.IntSize - 1
+	rules := state.DefaultRules()
+	rules.InitialTiles = []state.Tile{
+		{Position: state.Position{Row: 0, Column: 0}, Value: 1 << shift},
+		{Position: state.Position{Row: 0, Column: 1}, Value: 2},
+	}
+	session, err := state.NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	session.Down()
+	original := FromSession(session)
+
+	var buffer bytes.Buffer
+	if err := original.Write(&buffer); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	read, err := Read(&buffer)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(original, read) {
+		t.Fatalf("Replay changed after writing and reading:\n%+v\n%+v", original, read)
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	rules := `rules {"width":4,"height":4,"spawnPolicy":[{"value":2,"weight":1}],"startTiles":2,"targetTile":2048,"undoLimit":0}`
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "unknown format",
+			content:       "something else",
+			expectedError: "line 1",
+		},
+		{
+			name:          "unsupported version",
+			content:       "2048-replay 99",
+			expectedError: "unsupported version",
+		},
+		{
+			name:          "missing rules",
+			content:       "2048-replay 1\nseed 1\nstart 0:0:2",
+			expectedError: "line 3",
+		},
+		{
+			name:          "invalid direction",
+			content:       "2048-replay 1\nseed 1\n" + rules + "\nstart 0:0:2\n\n# comment\nmove sideways",
+			expectedError: "line 7",
+		},
+		{
+			name:          "invalid result",
+			content:       "2048-replay 1\nseed 1\n" + rules + "\nstart 0:0:2\nresult 4 2 2,0/0",
+			expectedError: "different length",
+		},
+		{
+			name:          "move after result",
+			content:       "2048-replay 1\nseed 1\n" + rules + "\nstart 0:0:2\nresult 0 2 2,0/0,0\nmove left",
+			expectedError: "line 6",
+		},
+		{
+			name:          "incomplete",
+			content:       "2048-replay 1\nseed 1\n",
+			expectedError: "incomplete",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := Read(strings.NewReader(test.content))
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("Expected error containing %q, got: %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestReplay_FramesErrors(t *testing.T) {
+	replay := &Replay{
+		Rules: state.DefaultRules(),
+		Start: []state.Tile{{Position: state.Position{Row: 0, Column: 0}, Value: 2}},
+		Moves: []state.LoggedMove{
+			{Direction: state.Right, Spawn: &state.Tile{Position: state.Position{Row: 0, Column: 3}, Value: 2}},
+		},
+	}
+	if _, err := replay.Frames(); err == nil || !strings.Contains(err.Error(), "occupied") {
+		t.Errorf("Expected error about occupied cell, got: %v", err)
+	}
+
+	replay.Moves[0].Direction = state.Left
+	if _, err := replay.Frames(); err == nil || !strings.Contains(err.Error(), "doesn't change") {
+		t.Errorf("Expected error about impossible move, got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package replay
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// VerificationError describes the first point at which a replay diverges
+// from the game re-simulated from its seed and rules.
+type VerificationError struct {
+	// Move is the number of the diverging move, starting at 1. It is 0 if
+	// the start tiles diverge and len(Moves)+1 if only the claimed result
+	// is wrong.
+	Move   int
+	Reason string
+}
+
+func (err *VerificationError) Error() string {
+	if err.Move == 0 {
+		return "start tiles: " + err.Reason
+	}
+	return fmt.Sprintf("move %d: %s", err.Move, err.Reason)
+}
+
+// Verify re-simulates the replay via a state.GameSession, using the seed
+// and rules of the replay, and checks that all moves and spawns are the
+// ones the game would have produced and that the claimed result matches
+// the end of the game. A replay without a result is never valid. The
+// returned error is a *VerificationError if the replay diverges.
+//
+// Moves made after winning imply that the player decided to keep playing.
+func (replay *Replay) Verify() (*state.GameSession, error) {
+	session, err := state.NewGameSession(nil, replay.Rules, replay.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	if !reflect.DeepEqual(normalizeTiles(session.StartTiles()), normalizeTiles(replay.Start)) {
+		return session, &VerificationError{
+			Move:   0,
+			Reason: fmt.Sprintf("recorded %s, but the game starts with %s", formatTiles(replay.Start), formatTiles(session.StartTiles())),
+		}
+	}
+
+	for index, move := range replay.Moves {
+		diverge := func(format string, arguments ...interface{}) error {
+			return &VerificationError{
+				Move:   index + 1,
+				Reason: fmt.Sprintf("%s: ", move.Direction) + fmt.Sprintf(format, arguments...),
+			}
+		}
+
+		if session.GameOver {
+			return session, diverge("the game is already over")
+		}
+		session.KeepPlaying()
+		result := session.Move(move.Direction)
+		if !result.Changed {
+			return session, diverge("doesn't change the board")
+		}
+		if !reflect.DeepEqual(result.Spawn, move.Spawn) {
+			return session, diverge("recorded spawn %s, but the game spawns %s", formatSpawn(move.Spawn), formatSpawn(result.Spawn))
+		}
+	}
+
+	end := &VerificationError{Move: len(replay.Moves) + 1}
+	claimed := replay.Result
+	switch {
+	case claimed == nil:
+		end.Reason = "the replay doesn't claim a result"
+	case claimed.Score != session.Score():
+		end.Reason = fmt.Sprintf("claimed score %d, but the game ends with %d", claimed.Score, session.Score())
+	case claimed.MaxTile != session.GameBoard.MaxTile():
+		end.Reason = fmt.Sprintf("claimed max tile %d, but the game ends with %d", claimed.MaxTile, session.GameBoard.MaxTile())
+	case !reflect.DeepEqual(claimed.Board, session.GameBoard):
+		end.Reason = fmt.Sprintf("claimed board %s, but the game ends with %s", claimed.Board.Format(), session.GameBoard.Format())
+	default:
+		return session, nil
+	}
+	return session, end
+}
+
+// normalizeTiles turns empty slices into nil, so that they compare equal.
+func normalizeTiles(tiles []state.Tile) []state.Tile {
+	if len(tiles) == 0 {
+		return nil
+	}
+	return tiles
+}
+
+func formatTiles(tiles []state.Tile) string {
+	if len(tiles) == 0 {
+		return "no tiles"
+	}
+
+	formatted := ""
+	for index, tile := range tiles {
+		if index > 0 {
+			formatted += " "
+		}
+		formatted += formatTile(tile)
+	}
+	return formatted
+}
+
+func formatSpawn(tile *state.Tile) string {
+	if tile == nil {
+		return "nothing"
+	}
+	return formatTile(*tile)
+}
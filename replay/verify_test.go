@@ -0,0 +1,109 @@
+package replay
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func TestReplay_Verify(t *testing.T) {
+	session := playSession(t, 40)
+	if _, err := FromSession(session).Verify(); err != nil {
+		t.Fatalf("Genuine replay failed verification: %s", err)
+	}
+
+	tests := []struct {
+		name         string
+		tamper       func(replay *Replay)
+		expectedMove int
+	}{
+		{
+			name: "start tiles",
+			tamper: func(replay *Replay) {
+				replay.Start[0].Value *= 2
+			},
+			expectedMove: 0,
+		},
+		{
+			name: "spawn",
+			tamper: func(replay *Replay) {
+				spawn := *replay.Moves[4].Spawn
+				spawn.Value = 6 - spawn.Value
+				replay.Moves[4].Spawn = &spawn
+			},
+			expectedMove: 5,
+		},
+		{
+			name: "seed",
+			tamper: func(replay *Replay) {
+				replay.Seed++
+			},
+			expectedMove: 0,
+		},
+		{
+			name: "score",
+			tamper: func(replay *Replay) {
+				replay.Result.Score += 4
+			},
+			expectedMove: session.MoveCount() + 1,
+		},
+		{
+			name: "board",
+			tamper: func(replay *Replay) {
+				replay.Result.Board[0][0] = 4096
+			},
+			expectedMove: session.MoveCount() + 1,
+		},
+		{
+			name: "no result",
+			tamper: func(replay *Replay) {
+				replay.Result = nil
+			},
+			expectedMove: session.MoveCount() + 1,
+		},
+		{
+			name: "truncated",
+			tamper: func(replay *Replay) {
+				replay.Moves = replay.Moves[:len(replay.Moves)-1]
+			},
+			expectedMove: session.MoveCount(),
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			replay := FromSession(session)
+			test.tamper(replay)
+
+			_, err := replay.Verify()
+			var verificationError *VerificationError
+			if !errors.As(err, &verificationError) {
+				t.Fatalf("Expected verification error, got: %v", err)
+			}
+			if verificationError.Move != test.expectedMove {
+				t.Errorf("Expected divergence at move %d, got: %s", test.expectedMove, err)
+			}
+		})
+	}
+}
+
+func TestReplay_VerifyKeepPlaying(t *testing.T) {
+	rules := state.DefaultRules()
+	rules.TargetTile = 4
+	session, err := state.NewGameSession(nil, rules, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for index := 0; index < 30 && !session.GameOver; index++ {
+		session.KeepPlaying()
+		session.Move(state.Directions[index%len(state.Directions)])
+	}
+	if !session.Won {
+		t.Fatal("Test game hasn't been won")
+	}
+
+	if _, err := FromSession(session).Verify(); err != nil {
+		t.Errorf("Replay continuing after a win failed verification: %s", err)
+	}
+}
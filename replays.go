@@ -0,0 +1,220 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/replay"
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/theme"
+	"github.com/gdamore/tcell/v2"
+)
+
+// playbackSpeeds are the available speeds in moves per second.
+var playbackSpeeds = []float64{0.5, 1, 2, 4, 8, 16, 32}
+
+// writeReplay stores the replay of the session in the given directory. The
+// file is named after the seed, so that the replay of a resumed game
+// replaces the earlier one.
+func writeReplay(directory string, session *state.GameSession) error {
+	if directory == "" || session.MoveCount() == 0 {
+		return nil
+	}
+
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+	path := filepath.Join(directory, fmt.Sprintf("%d.replay", session.Seed()))
+	return replay.FromSession(session).WriteFile(path)
+}
+
+// runReplay implements the "replay" subcommand, which plays back a replay
+// file in the terminal.
+func runReplay(args []string) int {
+	flags := flag.NewFlagSet("replay", flag.ExitOnError)
+	speed := flags.Float64("speed", 2, "initial playback speed in moves per second")
+	themeChoice := flags.String("theme", "", "theme, either one of "+strings.Join(theme.Names(), ", ")+" or the path of a JSON theme file (default depends on the terminal's colors)")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal replay [flags] <file>")
+		fmt.Fprintln(flags.Output(), "Plays back a replay recorded via --record.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	recording, err := replay.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	frames, err := recording.Frames()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid replay %s: %s\n", flags.Arg(0), err)
+		return 1
+	}
+
+	screen, err := createScreen()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer screen.Fini()
+	themes, themeIndex, err := loadThemes(*themeChoice, detectColorSupport(screen))
+	if err != nil {
+		screen.Fini()
+		fmt.Fprintf(os.Stderr, "invalid --theme: %s\n", err)
+		return 2
+	}
+
+	speedIndex := len(playbackSpeeds) - 1
+	for index, available := range playbackSpeeds {
+		if available >= *speed {
+			speedIndex = index
+			break
+		}
+	}
+	playReplay(screen, themes[themeIndex], frames, speedIndex)
+	return 0
+}
+
+func playbackDelay(speedIndex int) time.Duration {
+	return time.Duration(float64(time.Second) / playbackSpeeds[speedIndex])
+}
+
+// playReplay shows the frames until the user quits. Playback can be
+// paused, stepped through in both directions and sped up or slowed down.
+func playReplay(screen tcell.Screen, currentTheme *theme.Theme, frames []replay.Frame, speedIndex int) {
+	events := make(chan tcell.Event)
+	go func() {
+		for {
+			event := screen.PollEvent()
+			//Nil means the screen has been finalized.
+			if event == nil {
+				return
+			}
+			events <- event
+		}
+	}()
+
+	ticker := time.NewTicker(playbackDelay(speedIndex))
+	defer ticker.Stop()
+
+	var frameIndex int
+	var paused bool
+	for {
+		drawReplayFrame(screen, currentTheme, frames, frameIndex, playbackSpeeds[speedIndex], paused)
+
+		select {
+		case <-ticker.C:
+			if !paused && frameIndex < len(frames)-1 {
+				frameIndex++
+			}
+		case event := <-events:
+			keyEvent, isKeyEvent := event.(*tcell.EventKey)
+			if !isKeyEvent {
+				//Resizes only require redrawing.
+				screen.Sync()
+				continue
+			}
+
+			switch {
+			case keyEvent.Key() == tcell.KeyCtrlC || keyEvent.Key() == tcell.KeyEscape || eventIsRune(keyEvent, 'q'):
+				return
+			case eventIsRune(keyEvent, ' '):
+				paused = !paused
+			case keyEvent.Key() == tcell.KeyRight || eventIsRune(keyEvent, 'd'):
+				paused = true
+				if frameIndex < len(frames)-1 {
+					frameIndex++
+				}
+			case keyEvent.Key() == tcell.KeyLeft || eventIsRune(keyEvent, 'a'):
+				paused = true
+				if frameIndex > 0 {
+					frameIndex--
+				}
+			case keyEvent.Key() == tcell.KeyHome:
+				frameIndex = 0
+			case keyEvent.Key() == tcell.KeyEnd:
+				frameIndex = len(frames) - 1
+			case eventIsRune(keyEvent, '+'):
+				if speedIndex < len(playbackSpeeds)-1 {
+					speedIndex++
+					ticker.Reset(playbackDelay(speedIndex))
+				}
+			case eventIsRune(keyEvent, '-'):
+				if speedIndex > 0 {
+					speedIndex--
+					ticker.Reset(playbackDelay(speedIndex))
+				}
+			}
+		}
+	}
+}
+
+func drawReplayFrame(screen tcell.Screen, currentTheme *theme.Theme, frames []replay.Frame, frameIndex int, speed float64, paused bool) {
+	screen.Clear()
+	screen.Fill(' ', currentTheme.Background)
+	frame := frames[frameIndex]
+	//The status line and the controls follow a line of space.
+	const footerLines = 3
+	layout, fits := newLayout(screen, currentTheme, frame.Board, nil, footerLines)
+	if !fits {
+		drawTooSmall(screen, layout)
+		screen.Show()
+		return
+	}
+	layout.drawBoard(screen, frame.Board)
+
+	status := fmt.Sprintf("Move %d/%d  Score: %d  Speed: %g moves/s", frameIndex, len(frames)-1, frame.Score, speed)
+	if paused {
+		status += "  [paused]"
+	}
+	if frame.Move != nil {
+		status += "  Last move: " + frame.Move.Direction.String()
+	}
+	layout.drawFooter(screen, frame.Board, status,
+		"Space: Pause  Left/Right: Step  Home/End: Jump  +/-: Speed  Q: Quit")
+
+	if frame.Result.GameOver {
+		layout.drawMessageBox(screen, frame.Board, fmt.Sprintf("Game Over; Score: %d", frame.Score))
+	}
+	screen.Show()
+}
+
+// runVerify implements the "verify" subcommand, which checks a replay
+// against the game re-simulated from its seed and rules. It doesn't
+// require a terminal.
+func runVerify(args []string) int {
+	flags := flag.NewFlagSet("verify", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal verify <file>")
+		fmt.Fprintln(flags.Output(), "Checks that the moves, spawns and claimed result of a replay are genuine.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		flags.Usage()
+		return 2
+	}
+
+	recording, err := replay.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	session, err := recording.Verify()
+	if err != nil {
+		fmt.Printf("INVALID: %s\n", err)
+		return 1
+	}
+
+	fmt.Printf("VALID: score %d, max tile %d, %d moves\n", session.Score(), session.GameBoard.MaxTile(), session.MoveCount())
+	return 0
+}
@@ -0,0 +1,178 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/storage"
+)
+
+// scoreKeeper records games in the high score table and knows the best
+// score for the game currently being played.
+type scoreKeeper struct {
+	path       string
+	highScores []storage.HighScore
+	gameStart  time.Time
+	// started identifies the game in the high score table. Unlike
+	// gameStart, it isn't moved when resuming.
+	started  time.Time
+	recorded bool
+	// resumed is set if the game has been played on after recording it.
+	// It's only recorded again if it scored better than recordedScore.
+	resumed       bool
+	recordedScore uint
+	// player names the computer player that made moves in this game, if
+	// any.
+	player string
+	// duration is the time the game took, once it has been recorded.
+	duration time.Duration
+	// lastError is reported on exit, as there's no good way of showing
+	// it during the game.
+	lastError error
+}
+
+func newScoreKeeper() *scoreKeeper {
+	now := time.Now()
+	keeper := &scoreKeeper{gameStart: now, started: now}
+	keeper.path, keeper.lastError = storage.HighScoresPath()
+	if keeper.lastError == nil {
+		keeper.highScores, keeper.lastError = storage.LoadHighScores(keeper.path)
+	}
+	return keeper
+}
+
+// startGame has to be called whenever a new game starts.
+func (keeper *scoreKeeper) startGame() {
+	keeper.gameStart = time.Now()
+	keeper.started = keeper.gameStart
+	keeper.recorded = false
+	keeper.resumed = false
+	keeper.player = ""
+}
+
+// playedBy has to be called whenever a computer player makes a move, so
+// that the game isn't mistaken for one played by a human.
+func (keeper *scoreKeeper) playedBy(player string) {
+	keeper.player = player
+}
+
+// resume has to be called when a recorded game can be played on, for
+// example by undoing the last move of a finished game. The clock goes on
+// where it stopped.
+func (keeper *scoreKeeper) resume() {
+	if !keeper.recorded {
+		return
+	}
+	keeper.recorded = false
+	keeper.resumed = true
+	keeper.gameStart = time.Now().Add(-keeper.duration)
+}
+
+// record adds the game to the high score table, unless it has already
+// been recorded or hasn't even been started. A resumed game only replaces
+// its earlier entry if it scored better than before.
+func (keeper *scoreKeeper) record(session *state.GameSession) {
+	if keeper.recorded || session.MoveCount() == 0 {
+		return
+	}
+
+	keeper.recorded = true
+	keeper.duration = time.Since(keeper.gameStart).Round(time.Second)
+	if keeper.resumed && session.Score() <= keeper.recordedScore {
+		return
+	}
+	keeper.recordedScore = session.Score()
+	if keeper.path == "" {
+		return
+	}
+	rules := session.Rules()
+	highScores, err := storage.AddHighScore(keeper.path, storage.HighScore{
+		Score:    session.Score(),
+		MaxTile:  session.GameBoard.MaxTile(),
+		Moves:    session.MoveCount(),
+		Duration: keeper.duration,
+		Date:     time.Now(),
+		Width:    rules.Width,
+		Height:   rules.Height,
+		Variant:  rules.Variant(),
+		Undos:    session.UndoCount(),
+		Hints:    session.HintCount(),
+		Player:   keeper.player,
+		Seed:     session.Seed(),
+		Started:  keeper.started,
+	}, storage.DefaultHighScoreLimit)
+	if err != nil {
+		keeper.lastError = err
+		return
+	}
+	keeper.highScores = highScores
+}
+
+// elapsed returns the time spent on the current game. The time stops once
+// the game has been recorded.
+func (keeper *scoreKeeper) elapsed() time.Duration {
+	if keeper.recorded {
+		return keeper.duration
+	}
+	return time.Since(keeper.gameStart)
+}
+
+// best returns the best score for games played by the same rules as the
+// given session, including the session itself.
+func (keeper *scoreKeeper) best(session *state.GameSession) uint {
+	rules := session.Rules()
+	best := storage.BestScore(keeper.highScores, rules.Width, rules.Height, rules.Variant())
+	if session.Score() > best {
+		return session.Score()
+	}
+	return best
+}
+
+// runScores implements the "scores" subcommand, which prints the high
+// score table.
+func runScores(args []string) int {
+	flags := flag.NewFlagSet("scores", flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal scores")
+		fmt.Fprintln(flags.Output(), "Prints the high score table.")
+	}
+	flags.Parse(args)
+
+	path, err := storage.HighScoresPath()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	highScores, err := storage.LoadHighScores(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	if len(highScores) == 0 {
+		fmt.Println("No high scores yet.")
+		return 0
+	}
+	printHighScores(os.Stdout, highScores)
+	return 0
+}
+
+func printHighScores(output io.Writer, highScores []storage.HighScore) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "#\tSCORE\tMAX TILE\tMOVES\tDURATION\tDATE\tSIZE\tVARIANT\tUNDOS\tHINTS\tPLAYER")
+	for index, highScore := range highScores {
+		player := highScore.Player
+		if player == "" {
+			player = "human"
+		}
+		fmt.Fprintf(writer, "%d\t%d\t%d\t%d\t%s\t%s\t%s\t%s\t%d\t%d\t%s\n",
+			index+1, highScore.Score, highScore.MaxTile, highScore.Moves, highScore.Duration,
+			highScore.Date.Format("2006-01-02 15:04"), highScore.BoardSize(), highScore.Variant, highScore.Undos, highScore.Hints, player)
+	}
+	writer.Flush()
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+	"github.com/Bios-Marcel/2048-terminal/storage"
+)
+
+func TestScoreKeeper_Resume(t *testing.T) {
+	rules := state.DefaultRules()
+	rules.UndoLimit = -1
+	rules.InitialTiles = []state.Tile{
+		{Position: state.Position{Row: 0, Column: 0}, Value: 2},
+		{Position: state.Position{Row: 0, Column: 1}, Value: 2},
+	}
+	session, err := state.NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	session.Down()
+
+	start := time.Now().Add(-time.Minute)
+	keeper := &scoreKeeper{
+		path:      filepath.Join(t.TempDir(), "highscores.json"),
+		gameStart: start,
+		started:   start,
+	}
+	keeper.record(session)
+	if len(keeper.highScores) != 1 {
+		t.Fatalf("Expected game to be recorded once, got %d entries", len(keeper.highScores))
+	}
+	if keeper.elapsed() != time.Minute {
+		t.Errorf("Expected clock to stop at 1m, got %s", keeper.elapsed())
+	}
+
+	//Recording the same result again after resuming mustn't add an entry.
+	keeper.resume()
+	if keeper.elapsed() < time.Minute {
+		t.Errorf("Expected clock to go on from 1m, got %s", keeper.elapsed())
+	}
+	keeper.record(session)
+	if len(keeper.highScores) != 1 {
+		t.Fatalf("Expected unchanged result to not be recorded again, got %d entries", len(keeper.highScores))
+	}
+
+	//A better result after resuming replaces the earlier entry.
+	keeper.resume()
+	session.Left()
+	keeper.record(session)
+	if len(keeper.highScores) != 1 || keeper.highScores[0].Score != session.Score() {
+		t.Fatalf("Expected better result to replace the earlier entry, got %+v", keeper.highScores)
+	}
+
+	//Another game with the same seed gets its own entry.
+	keeper.startGame()
+	keeper.record(session)
+	if len(keeper.highScores) != 2 {
+		t.Fatalf("Expected new game to be added, got %+v", keeper.highScores)
+	}
+}
+
+func TestScoreKeeper_PlayedBy(t *testing.T) {
+	session, err := state.NewGameSession(nil, state.DefaultRules(), 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	session.Left()
+	session.Down()
+
+	keeper := &scoreKeeper{
+		path:      filepath.Join(t.TempDir(), "highscores.json"),
+		gameStart: time.Now(),
+	}
+	keeper.playedBy("greedy")
+	keeper.record(session)
+	if len(keeper.highScores) != 1 || keeper.highScores[0].Player != "greedy" {
+		t.Fatalf("Expected autoplayed game to be marked, got %+v", keeper.highScores)
+	}
+
+	//A new game is played by a human again, until the computer moves.
+	keeper.startGame()
+	if keeper.player != "" {
+		t.Errorf("Expected new game to not be marked, got %q", keeper.player)
+	}
+}
+
+func TestPrintHighScores(t *testing.T) {
+	var output bytes.Buffer
+	printHighScores(&output, []storage.HighScore{
+		{Score: 200, Width: 4, Height: 4, Variant: "classic"},
+		{Score: 100, Width: 4, Height: 4, Variant: "classic", Player: "expectimax"},
+	})
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("Expected header and two entries, got:\n%s", output.String())
+	}
+	if !strings.HasSuffix(lines[0], "PLAYER") || !strings.HasSuffix(lines[1], "human") || !strings.HasSuffix(lines[2], "expectimax") {
+		t.Errorf("Expected player column, got:\n%s", output.String())
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+
+	"github.com/Bios-Marcel/2048-terminal/server"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// runServe implements the "serve" subcommand, which offers an HTTP/JSON
+// API for playing games.
+func runServe(args []string) int {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	address := flags.String("addr", "localhost:2048", "address to listen on; only use localhost, as there's no authentication")
+	undoLimit := flags.Int("undo", 10, "default amount of moves that can be undone in a row, 0 disables undo, -1 is unlimited")
+	maxSessions := flags.Int("max-sessions", 1000, "maximum amount of sessions kept in memory")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal serve [flags]")
+		fmt.Fprintln(flags.Output(), "Serves an HTTP/JSON API for playing games. See the README for the endpoints.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	rules := state.DefaultRules()
+	rules.UndoLimit = *undoLimit
+
+	fmt.Printf("Listening on http://%s\n", *address)
+	if err := http.ListenAndServe(*address, server.New(rules, *maxSessions)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
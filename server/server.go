@@ -0,0 +1,317 @@
+// Package server exposes game sessions via an HTTP/JSON API, so that other
+// programs can play without a terminal.
+//
+// All requests and responses are JSON encoded. Errors are returned as
+// {"error": "..."} with a fitting status code. The API consists of:
+//
+//	GET    /sessions                    list all sessions
+//	POST   /sessions                    create a session
+//	GET    /sessions/{id}               get the state of a session
+//	DELETE /sessions/{id}               delete a session
+//	POST   /sessions/{id}/move          move, given {"direction": "left"}
+//	POST   /sessions/{id}/undo          undo the last move
+//	POST   /sessions/{id}/redo          redo the last undone move
+//	POST   /sessions/{id}/keep-playing  keep playing after winning
+//
+// Sessions are created from {"width": 4, "height": 4, "seed": 1, "rules":
+// {...}}, where all fields are optional. "rules" are the JSON encoded
+// state.Rules, with missing fields taken from the server's default rules.
+// "width" and "height" override the size given by the rules.
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Server is an http.Handler that manages game sessions in memory.
+type Server struct {
+	defaultRules state.Rules
+	maxSessions  int
+
+	mutex    sync.Mutex
+	sessions map[string]*state.GameSession
+	lastID   int
+}
+
+// New produces a server that creates sessions based on the given rules.
+// At most maxSessions can exist at the same time.
+func New(defaultRules state.Rules, maxSessions int) *Server {
+	return &Server{
+		defaultRules: defaultRules,
+		maxSessions:  maxSessions,
+		sessions:     make(map[string]*state.GameSession),
+	}
+}
+
+// Session is the state of a session as returned by the API.
+type Session struct {
+	ID               string      `json:"id"`
+	Seed             int64       `json:"seed"`
+	Rules            state.Rules `json:"rules"`
+	Board            state.Board `json:"board"`
+	Score            uint        `json:"score"`
+	MaxTile          uint        `json:"maxTile"`
+	MoveCount        int         `json:"moveCount"`
+	GameOver         bool        `json:"gameOver"`
+	Won              bool        `json:"won"`
+	AwaitingDecision bool        `json:"awaitingDecision"`
+	CanUndo          bool        `json:"canUndo"`
+	CanRedo          bool        `json:"canRedo"`
+	UndoCount        int         `json:"undoCount"`
+}
+
+// CreateRequest is the body of a request creating a session.
+type CreateRequest struct {
+	Width  int              `json:"width"`
+	Height int              `json:"height"`
+	Seed   *int64           `json:"seed"`
+	Rules  *json.RawMessage `json:"rules"`
+}
+
+// MoveRequest is the body of a move request.
+type MoveRequest struct {
+	Direction *state.Direction `json:"direction"`
+}
+
+// MoveResponse is the response to a move request.
+type MoveResponse struct {
+	Result  state.MoveResult `json:"result"`
+	Session Session          `json:"session"`
+}
+
+// httpError is an error that is reported with a certain status code.
+type httpError struct {
+	status  int
+	message string
+}
+
+func (err *httpError) Error() string {
+	return err.message
+}
+
+func errorf(status int, format string, arguments ...interface{}) error {
+	return &httpError{status: status, message: fmt.Sprintf(format, arguments...)}
+}
+
+// ServeHTTP implements http.Handler.
+func (server *Server) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	response, err := server.route(request)
+	if err != nil {
+		status := http.StatusInternalServerError
+		var statusError *httpError
+		if errors.As(err, &statusError) {
+			status = statusError.status
+		}
+		writeJSON(writer, status, map[string]string{"error": err.Error()})
+		return
+	}
+
+	status := http.StatusOK
+	if request.Method == http.MethodPost && request.URL.Path == "/sessions" {
+		status = http.StatusCreated
+	}
+	writeJSON(writer, status, response)
+}
+
+func writeJSON(writer http.ResponseWriter, status int, value interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(value)
+}
+
+// route dispatches the request and returns the value to respond with.
+func (server *Server) route(request *http.Request) (interface{}, error) {
+	parts := strings.Split(strings.Trim(request.URL.Path, "/"), "/")
+	if parts[0] != "sessions" || len(parts) > 3 {
+		return nil, errorf(http.StatusNotFound, "unknown path %q", request.URL.Path)
+	}
+
+	if len(parts) == 1 {
+		switch request.Method {
+		case http.MethodGet:
+			return server.list(), nil
+		case http.MethodPost:
+			return server.create(request)
+		}
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s isn't allowed", request.Method)
+	}
+
+	id := parts[1]
+	if len(parts) == 2 {
+		switch request.Method {
+		case http.MethodGet:
+			return server.withSession(id, func(session *state.GameSession) (interface{}, error) {
+				return view(id, session), nil
+			})
+		case http.MethodDelete:
+			return server.delete(id)
+		}
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s isn't allowed", request.Method)
+	}
+
+	if request.Method != http.MethodPost {
+		return nil, errorf(http.StatusMethodNotAllowed, "method %s isn't allowed", request.Method)
+	}
+	switch parts[2] {
+	case "move":
+		var move MoveRequest
+		if err := json.NewDecoder(request.Body).Decode(&move); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid move: %s", err)
+		}
+		if move.Direction == nil {
+			return nil, errorf(http.StatusBadRequest, "invalid move: direction is missing")
+		}
+		return server.withSession(id, func(session *state.GameSession) (interface{}, error) {
+			result := session.Move(*move.Direction)
+			return MoveResponse{Result: result, Session: view(id, session)}, nil
+		})
+	case "undo":
+		return server.withSession(id, func(session *state.GameSession) (interface{}, error) {
+			if !session.Undo() {
+				return nil, errorf(http.StatusConflict, "there's nothing to undo")
+			}
+			return view(id, session), nil
+		})
+	case "redo":
+		return server.withSession(id, func(session *state.GameSession) (interface{}, error) {
+			if !session.Redo() {
+				return nil, errorf(http.StatusConflict, "there's nothing to redo")
+			}
+			return view(id, session), nil
+		})
+	case "keep-playing":
+		return server.withSession(id, func(session *state.GameSession) (interface{}, error) {
+			if !session.AwaitingDecision() {
+				return nil, errorf(http.StatusConflict, "the game hasn't just been won")
+			}
+			session.KeepPlaying()
+			return view(id, session), nil
+		})
+	}
+	return nil, errorf(http.StatusNotFound, "unknown action %q", parts[2])
+}
+
+// withSession calls the function with the lock of the session held.
+func (server *Server) withSession(id string, function func(session *state.GameSession) (interface{}, error)) (interface{}, error) {
+	server.mutex.Lock()
+	session, exists := server.sessions[id]
+	server.mutex.Unlock()
+	if !exists {
+		return nil, errorf(http.StatusNotFound, "session %q doesn't exist", id)
+	}
+
+	session.Mutex.Lock()
+	defer session.Mutex.Unlock()
+	return function(session)
+}
+
+func (server *Server) create(request *http.Request) (interface{}, error) {
+	var body CreateRequest
+	//All fields are optional, so even the body itself is.
+	if err := json.NewDecoder(request.Body).Decode(&body); err != nil && err != io.EOF {
+		return nil, errorf(http.StatusBadRequest, "invalid session: %s", err)
+	}
+
+	rules := server.defaultRules
+	//Unmarshalling reuses slices, which must not modify the defaults.
+	rules.SpawnPolicy = append(state.SpawnPolicy(nil), rules.SpawnPolicy...)
+	rules.StartSpawnPolicy = append(state.SpawnPolicy(nil), rules.StartSpawnPolicy...)
+	rules.InitialTiles = append([]state.Tile(nil), rules.InitialTiles...)
+	if body.Rules != nil {
+		if err := json.Unmarshal(*body.Rules, &rules); err != nil {
+			return nil, errorf(http.StatusBadRequest, "invalid rules: %s", err)
+		}
+	}
+	if body.Width != 0 {
+		rules.Width = body.Width
+	}
+	if body.Height != 0 {
+		rules.Height = body.Height
+	}
+	seed := state.RandomSeed()
+	if body.Seed != nil {
+		seed = *body.Seed
+	}
+
+	session, err := state.NewGameSession(nil, rules, seed)
+	if err != nil {
+		return nil, errorf(http.StatusBadRequest, "invalid rules: %s", err)
+	}
+
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if len(server.sessions) >= server.maxSessions {
+		return nil, errorf(http.StatusServiceUnavailable, "there are already %d sessions", len(server.sessions))
+	}
+	server.lastID++
+	id := strconv.Itoa(server.lastID)
+	server.sessions[id] = session
+	return view(id, session), nil
+}
+
+func (server *Server) delete(id string) (interface{}, error) {
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+	if _, exists := server.sessions[id]; !exists {
+		return nil, errorf(http.StatusNotFound, "session %q doesn't exist", id)
+	}
+	delete(server.sessions, id)
+	return map[string]string{"deleted": id}, nil
+}
+
+// list returns all sessions, oldest first.
+func (server *Server) list() []Session {
+	server.mutex.Lock()
+	ids := make([]string, 0, len(server.sessions))
+	sessions := make(map[string]*state.GameSession, len(server.sessions))
+	for id, session := range server.sessions {
+		ids = append(ids, id)
+		sessions[id] = session
+	}
+	server.mutex.Unlock()
+
+	sort.Slice(ids, func(a, b int) bool {
+		//IDs are increasing numbers.
+		first, _ := strconv.Atoi(ids[a])
+		second, _ := strconv.Atoi(ids[b])
+		return first < second
+	})
+	views := make([]Session, 0, len(ids))
+	for _, id := range ids {
+		session := sessions[id]
+		session.Mutex.Lock()
+		views = append(views, view(id, session))
+		session.Mutex.Unlock()
+	}
+	return views
+}
+
+// view captures the state of the session. The lock of the session has to
+// be held.
+func view(id string, session *state.GameSession) Session {
+	return Session{
+		ID:               id,
+		Seed:             session.Seed(),
+		Rules:            session.Rules(),
+		Board:            session.GameBoard.Copy(),
+		Score:            session.Score(),
+		MaxTile:          session.GameBoard.MaxTile(),
+		MoveCount:        session.MoveCount(),
+		GameOver:         session.GameOver,
+		Won:              session.Won,
+		AwaitingDecision: session.AwaitingDecision(),
+		CanUndo:          session.CanUndo(),
+		CanRedo:          session.CanRedo(),
+		UndoCount:        session.UndoCount(),
+	}
+}
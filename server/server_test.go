@@ -0,0 +1,122 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func newTestServer() *Server {
+	rules := state.DefaultRules()
+	rules.UndoLimit = -1
+	return New(rules, 2)
+}
+
+// request performs a request and decodes the response into result, which
+// might be nil.
+func request(t *testing.T, server *Server, method, path, body string, expectedStatus int, result interface{}) {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+	if recorder.Code != expectedStatus {
+		t.Fatalf("%s %s: expected status %d, got %d: %s", method, path, expectedStatus, recorder.Code, recorder.Body)
+	}
+	if result != nil {
+		if err := json.NewDecoder(recorder.Body).Decode(result); err != nil {
+			t.Fatalf("%s %s: invalid response: %s", method, path, err)
+		}
+	}
+}
+
+func TestServer_Play(t *testing.T) {
+	server := newTestServer()
+
+	var created Session
+	request(t, server, http.MethodPost, "/sessions", `{"width":5,"height":3,"seed":7,"rules":{"targetTile":0}}`, http.StatusCreated, &created)
+	if created.ID != "1" || created.Seed != 7 || len(created.Board) != 3 || len(created.Board[0]) != 5 {
+		t.Fatalf("Unexpected session: %+v", created)
+	}
+	if created.Rules.TargetTile != 0 || created.Rules.UndoLimit != -1 || created.Rules.StartTiles != 2 {
+		t.Errorf("Rules haven't been merged with the defaults: %+v", created.Rules)
+	}
+
+	var moved MoveResponse
+	for _, direction := range []string{"left", "up", "right", "down"} {
+		request(t, server, http.MethodPost, "/sessions/1/move", `{"direction":"`+direction+`"}`, http.StatusOK, &moved)
+		if moved.Result.Changed {
+			break
+		}
+	}
+	if !moved.Result.Changed || moved.Session.MoveCount != 1 || moved.Result.Spawn == nil {
+		t.Fatalf("Unexpected move response: %+v", moved)
+	}
+
+	var undone Session
+	request(t, server, http.MethodPost, "/sessions/1/undo", "", http.StatusOK, &undone)
+	if undone.MoveCount != 0 || !undone.CanRedo {
+		t.Errorf("Unexpected session after undo: %+v", undone)
+	}
+	request(t, server, http.MethodPost, "/sessions/1/undo", "", http.StatusConflict, nil)
+	request(t, server, http.MethodPost, "/sessions/1/redo", "", http.StatusOK, nil)
+
+	var fetched Session
+	request(t, server, http.MethodGet, "/sessions/1", "", http.StatusOK, &fetched)
+	if fetched.MoveCount != 1 || fetched.Score != moved.Session.Score {
+		t.Errorf("Unexpected session after redo: %+v", fetched)
+	}
+}
+
+func TestServer_Sessions(t *testing.T) {
+	server := newTestServer()
+	request(t, server, http.MethodPost, "/sessions", "", http.StatusCreated, nil)
+	request(t, server, http.MethodPost, "/sessions", `{"seed":1}`, http.StatusCreated, nil)
+	request(t, server, http.MethodPost, "/sessions", "", http.StatusServiceUnavailable, nil)
+
+	var sessions []Session
+	request(t, server, http.MethodGet, "/sessions", "", http.StatusOK, &sessions)
+	if len(sessions) != 2 || sessions[0].ID != "1" || sessions[1].ID != "2" {
+		t.Fatalf("Unexpected sessions: %+v", sessions)
+	}
+
+	request(t, server, http.MethodDelete, "/sessions/1", "", http.StatusOK, nil)
+	request(t, server, http.MethodGet, "/sessions/1", "", http.StatusNotFound, nil)
+	request(t, server, http.MethodGet, "/sessions", "", http.StatusOK, &sessions)
+	if len(sessions) != 1 || sessions[0].ID != "2" {
+		t.Errorf("Unexpected sessions after deleting: %+v", sessions)
+	}
+}
+
+func TestServer_Errors(t *testing.T) {
+	server := newTestServer()
+	request(t, server, http.MethodPost, "/sessions", "", http.StatusCreated, nil)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		status int
+	}{
+		{method: http.MethodGet, path: "/games", status: http.StatusNotFound},
+		{method: http.MethodPut, path: "/sessions", status: http.StatusMethodNotAllowed},
+		{method: http.MethodGet, path: "/sessions/1/move", status: http.StatusMethodNotAllowed},
+		{method: http.MethodPost, path: "/sessions/1/jump", status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/sessions/9/move", body: `{"direction":"up"}`, status: http.StatusNotFound},
+		{method: http.MethodPost, path: "/sessions/1/move", body: `{"direction":"sideways"}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/sessions/1/move", body: `{}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/sessions/1/keep-playing", status: http.StatusConflict},
+		{method: http.MethodPost, path: "/sessions", body: `{"width":1}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/sessions", body: `{"width":100000,"height":100000}`, status: http.StatusBadRequest},
+		{method: http.MethodPost, path: "/sessions", body: `{"rules":[]}`, status: http.StatusBadRequest},
+	}
+	for _, test := range tests {
+		var response map[string]string
+		request(t, server, test.method, test.path, test.body, test.status, &response)
+		if response["error"] == "" {
+			t.Errorf("%s %s: expected error message", test.method, test.path)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/simulation"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// simulationReport is the output of the "simulate" subcommand.
+type simulationReport struct {
+	Strategy string `json:"strategy"`
+	Seed     int64  `json:"seed"`
+	Workers  int    `json:"workers"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+	simulation.Stats
+}
+
+// runSimulate implements the "simulate" subcommand, which plays games
+// without a terminal UI and prints aggregated statistics.
+func runSimulate(args []string) int {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	games := flags.Int("games", 100, "amount of games to play")
+	workers := flags.Int("workers", runtime.NumCPU(), "amount of games played in parallel")
+	strategyName := flags.String("strategy", "greedy", "strategy to play with: "+strings.Join(ai.StrategyNames, ", "))
+	depth := flags.Int("depth", ai.DefaultDepth, "search depth of the expectimax strategy")
+	seed := flags.Int64("seed", 0, "seed of the batch; batches with the same seed and workers are identical (default random)")
+	size := flags.String("size", "4", "size of the board, either N for a square board or WIDTHxHEIGHT")
+	spawn := flags.String("spawn", "2:0.9,4:0.1", "weighted tile values to spawn, in the form VALUE:WEIGHT,...")
+	format := flags.String("format", "text", "output format: text, json or csv")
+	flags.Usage = func() {
+		fmt.Fprintln(flags.Output(), "Usage: 2048-terminal simulate [flags]")
+		fmt.Fprintln(flags.Output(), "Plays games with a strategy and prints statistics about them.")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+
+	rules := state.DefaultRules()
+	//Undo is of no use for simulations and only costs memory.
+	rules.UndoLimit = 0
+	var err error
+	if rules.Width, rules.Height, err = parseSize(*size); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --size: %s\n", err)
+		return 2
+	}
+	if rules.SpawnPolicy, err = parseSpawnPolicy(*spawn); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --spawn: %s\n", err)
+		return 2
+	}
+	if *format != "text" && *format != "json" && *format != "csv" {
+		fmt.Fprintf(os.Stderr, "invalid --format %q, expected text, json or csv\n", *format)
+		return 2
+	}
+	if *depth < 1 {
+		fmt.Fprintln(os.Stderr, "invalid --depth: has to be at least 1")
+		return 2
+	}
+	newStrategy := func(seed int64) (ai.Strategy, error) {
+		strategy, err := ai.NewStrategy(*strategyName, rules, seed)
+		if solver, isSolver := strategy.(*ai.Solver); isSolver {
+			solver.Depth = *depth
+		}
+		return strategy, err
+	}
+	if _, err := newStrategy(0); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid --strategy: %s\n", err)
+		return 2
+	}
+
+	batchSeed := *seed
+	if !isFlagSet(flags, "seed") {
+		batchSeed = state.RandomSeed()
+	}
+
+	played, duration, err := simulation.Run(simulation.Config{
+		Games:       *games,
+		Workers:     *workers,
+		Rules:       rules,
+		Seed:        batchSeed,
+		NewStrategy: newStrategy,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	report := simulationReport{
+		Strategy: *strategyName,
+		Seed:     batchSeed,
+		Workers:  *workers,
+		Width:    rules.Width,
+		Height:   rules.Height,
+		Stats:    simulation.Aggregate(played, duration),
+	}
+	switch *format {
+	case "json":
+		err = printSimulationJSON(os.Stdout, report)
+	case "csv":
+		err = printSimulationCSV(os.Stdout, report)
+	default:
+		printSimulationText(os.Stdout, report)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+func printSimulationText(output io.Writer, report simulationReport) {
+	writer := tabwriter.NewWriter(output, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(writer, "Strategy:\t%s\n", report.Strategy)
+	fmt.Fprintf(writer, "Board:\t%dx%d\n", report.Width, report.Height)
+	fmt.Fprintf(writer, "Games:\t%d (%d workers, seed %d)\n", report.Games, report.Workers, report.Seed)
+	fmt.Fprintf(writer, "Duration:\t%.2fs (%.1f games/s)\n", report.Duration, report.GamesPerSecond)
+	fmt.Fprintf(writer, "Average moves:\t%.1f\n", report.AverageMoves)
+	fmt.Fprintf(writer, "Average score:\t%.1f\n", report.AverageScore)
+	fmt.Fprintf(writer, "Min score:\t%d\n", report.MinScore)
+	for _, percentile := range simulation.Percentiles {
+		fmt.Fprintf(writer, "Score percentile %d:\t%d\n", percentile, report.ScorePercentiles[percentile])
+	}
+	fmt.Fprintf(writer, "Max score:\t%d\n", report.MaxScore)
+	for _, rate := range report.TileRates {
+		fmt.Fprintf(writer, "Reached %d:\t%.1f%% (%d)\n", rate.Tile, rate.Rate*100, rate.Games)
+	}
+	writer.Flush()
+}
+
+func printSimulationJSON(output io.Writer, report simulationReport) error {
+	encoder := json.NewEncoder(output)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// printSimulationCSV prints one metric per row, so that reports of
+// different batches can easily be joined.
+func printSimulationCSV(output io.Writer, report simulationReport) error {
+	formatFloat := func(value float64) string {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	records := [][]string{
+		{"metric", "value"},
+		{"strategy", report.Strategy},
+		{"width", strconv.Itoa(report.Width)},
+		{"height", strconv.Itoa(report.Height)},
+		{"games", strconv.Itoa(report.Games)},
+		{"workers", strconv.Itoa(report.Workers)},
+		{"seed", strconv.FormatInt(report.Seed, 10)},
+		{"duration_seconds", formatFloat(report.Duration)},
+		{"games_per_second", formatFloat(report.GamesPerSecond)},
+		{"average_moves", formatFloat(report.AverageMoves)},
+		{"average_score", formatFloat(report.AverageScore)},
+		{"min_score", strconv.FormatUint(uint64(report.MinScore), 10)},
+		{"max_score", strconv.FormatUint(uint64(report.MaxScore), 10)},
+	}
+	for _, percentile := range simulation.Percentiles {
+		records = append(records, []string{
+			fmt.Sprintf("score_p%d", percentile),
+			strconv.FormatUint(uint64(report.ScorePercentiles[percentile]), 10),
+		})
+	}
+	for _, rate := range report.TileRates {
+		records = append(records, []string{fmt.Sprintf("reached_%d", rate.Tile), formatFloat(rate.Rate)})
+	}
+
+	writer := csv.NewWriter(output)
+	writer.WriteAll(records)
+	return writer.Error()
+}
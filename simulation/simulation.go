@@ -0,0 +1,213 @@
+// Package simulation plays many games headlessly with a strategy and
+// aggregates the results. It's meant for comparing strategies and rules
+// and doesn't depend on any terminal.
+package simulation
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// Config describes a batch of games.
+type Config struct {
+	Games   int
+	Workers int
+	// Rules are the rules of all games. Undo is irrelevant for
+	// simulations, so the undo limit is ignored.
+	Rules state.Rules
+	// Seed determines all games. Batches with the same seed, rules and
+	// amount of workers produce the same games.
+	Seed int64
+	// NewStrategy creates the strategy of a worker. seed is the seed of
+	// the worker's random number generator.
+	NewStrategy func(seed int64) (ai.Strategy, error)
+}
+
+// Game is the result of a single game.
+type Game struct {
+	Seed    int64
+	Score   uint
+	MaxTile uint
+	Moves   int
+}
+
+// Run plays all games and returns them in the order they have been
+// assigned to the workers, which is independent of scheduling. Games keep
+// going after reaching the target tile, until no move is possible.
+func Run(config Config) ([]Game, time.Duration, error) {
+	if config.Games < 0 {
+		return nil, 0, errors.New("amount of games must not be negative")
+	}
+	if config.Workers < 1 {
+		return nil, 0, errors.New("at least one worker is required")
+	}
+	if err := config.Rules.Validate(); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	games := make([]Game, config.Games)
+	errs := make([]error, config.Workers)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < config.Workers; worker++ {
+		waitGroup.Add(1)
+		go func(worker int) {
+			defer waitGroup.Done()
+			errs[worker] = runWorker(config, worker, games)
+		}(worker)
+	}
+	waitGroup.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	return games, time.Since(start), nil
+}
+
+// runWorker plays every config.Workers-th game, starting at the game with
+// the index of the worker. Each worker has its own random number
+// generator, so the games don't depend on the order the workers run in.
+func runWorker(config Config, worker int, games []Game) error {
+	random := rand.New(rand.NewSource(config.Seed + int64(worker)))
+	strategy, err := config.NewStrategy(random.Int63())
+	if err != nil {
+		return err
+	}
+
+	for index := worker; index < len(games); index += config.Workers {
+		game, err := Play(config.Rules, random.Int63(), strategy)
+		if err != nil {
+			return err
+		}
+		games[index] = game
+	}
+	return nil
+}
+
+// Play plays a single game with the given strategy until no move is
+// possible. An error is returned if the strategy chooses a move that
+// doesn't change the board. 4x4 games are played on a state.Bitboard,
+// but are the same as games played by a state.GameSession.
+func Play(rules state.Rules, seed int64, strategy ai.Strategy) (Game, error) {
+	session, err := state.NewBitboardSession(rules, seed)
+	if err != nil {
+		return Game{}, err
+	}
+
+	for !session.GameOver() {
+		direction, ok := strategy.NextMove(session.Board())
+		if !ok {
+			break
+		}
+		if !session.Move(direction).Changed {
+			return Game{}, errors.New("strategy chose " + direction.String() + ", which doesn't change the board")
+		}
+	}
+
+	return Game{
+		Seed:    seed,
+		Score:   session.Score(),
+		MaxTile: session.MaxTile(),
+		Moves:   session.MoveCount(),
+	}, nil
+}
+
+// TileRate is the share of games that reached a tile.
+type TileRate struct {
+	Tile  uint    `json:"tile"`
+	Games int     `json:"games"`
+	Rate  float64 `json:"rate"`
+}
+
+// Stats aggregates the results of a batch of games.
+type Stats struct {
+	Games    int     `json:"games"`
+	Duration float64 `json:"durationSeconds"`
+	// GamesPerSecond is measured in wall-clock time, so it includes the
+	// benefit of running multiple workers.
+	GamesPerSecond float64 `json:"gamesPerSecond"`
+	AverageMoves   float64 `json:"averageMoves"`
+	AverageScore   float64 `json:"averageScore"`
+	MinScore       uint    `json:"minScore"`
+	MaxScore       uint    `json:"maxScore"`
+	// ScorePercentiles maps percentiles, such as 50 for the median, to
+	// scores. See Percentiles for the included percentiles.
+	ScorePercentiles map[int]uint `json:"scorePercentiles"`
+	// TileRates lists how many games reached each tile, starting at 512
+	// and ending with the biggest tile reached, but at least with 2048.
+	TileRates []TileRate `json:"tileRates"`
+}
+
+// Percentiles are the score percentiles included in Stats.
+var Percentiles = []int{10, 25, 50, 75, 90, 99}
+
+const (
+	// minReportedTile is the smallest tile included in Stats.TileRates,
+	// as smaller tiles are reached by practically every game.
+	minReportedTile = 512
+	// maxAlwaysReportedTile is included in Stats.TileRates even if no game
+	// reached it, as it's the tile that wins the classic game.
+	maxAlwaysReportedTile = 2048
+)
+
+// Aggregate computes the statistics of the given games, which took the
+// given time to play.
+func Aggregate(games []Game, duration time.Duration) Stats {
+	stats := Stats{
+		Games:            len(games),
+		Duration:         duration.Seconds(),
+		ScorePercentiles: make(map[int]uint, len(Percentiles)),
+	}
+	if len(games) == 0 {
+		return stats
+	}
+	if duration > 0 {
+		stats.GamesPerSecond = float64(len(games)) / duration.Seconds()
+	}
+
+	scores := make([]uint, 0, len(games))
+	var totalScore, totalMoves float64
+	maxTile := uint(maxAlwaysReportedTile)
+	for _, game := range games {
+		scores = append(scores, game.Score)
+		totalScore += float64(game.Score)
+		totalMoves += float64(game.Moves)
+		if game.MaxTile > maxTile {
+			maxTile = game.MaxTile
+		}
+	}
+	sort.Slice(scores, func(a, b int) bool { return scores[a] < scores[b] })
+
+	stats.AverageScore = totalScore / float64(len(games))
+	stats.AverageMoves = totalMoves / float64(len(games))
+	stats.MinScore = scores[0]
+	stats.MaxScore = scores[len(scores)-1]
+	for _, percentile := range Percentiles {
+		//Nearest-rank method.
+		rank := (percentile*len(scores) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		stats.ScorePercentiles[percentile] = scores[rank-1]
+	}
+
+	for tile := uint(minReportedTile); tile <= maxTile; tile *= 2 {
+		rate := TileRate{Tile: tile}
+		for _, game := range games {
+			if game.MaxTile >= tile {
+				rate.Games++
+			}
+		}
+		rate.Rate = float64(rate.Games) / float64(len(games))
+		stats.TileRates = append(stats.TileRates, rate)
+	}
+	return stats
+}
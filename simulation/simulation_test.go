@@ -0,0 +1,90 @@
+package simulation
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bios-Marcel/2048-terminal/ai"
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func testConfig(workers int) Config {
+	rules := state.DefaultRules()
+	rules.UndoLimit = 0
+	return Config{
+		Games:   20,
+		Workers: workers,
+		Rules:   rules,
+		Seed:    42,
+		NewStrategy: func(seed int64) (ai.Strategy, error) {
+			return ai.NewRandom(seed), nil
+		},
+	}
+}
+
+func TestRun_Reproducible(t *testing.T) {
+	first, _, err := Run(testConfig(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	second, _, err := Run(testConfig(3))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if len(first) != 20 {
+		t.Fatalf("Expected 20 games, got %d", len(first))
+	}
+	if !reflect.DeepEqual(first, second) {
+		t.Error("Batches with the same seed and workers differ")
+	}
+	for _, game := range first {
+		if game.Moves == 0 || game.MaxTile == 0 {
+			t.Errorf("Game hasn't been played: %+v", game)
+		}
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	config := testConfig(0)
+	if _, _, err := Run(config); err == nil {
+		t.Error("Expected error without workers")
+	}
+
+	config = testConfig(1)
+	config.Rules.Width = 0
+	if _, _, err := Run(config); err == nil {
+		t.Error("Expected error for invalid rules")
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	games := []Game{
+		{Score: 100, MaxTile: 256, Moves: 10},
+		{Score: 400, MaxTile: 512, Moves: 20},
+		{Score: 300, MaxTile: 2048, Moves: 30},
+		{Score: 200, MaxTile: 1024, Moves: 40},
+	}
+	stats := Aggregate(games, 2*time.Second)
+
+	if stats.GamesPerSecond != 2 || stats.AverageMoves != 25 || stats.AverageScore != 250 {
+		t.Errorf("Unexpected averages: %+v", stats)
+	}
+	if stats.MinScore != 100 || stats.MaxScore != 400 || stats.ScorePercentiles[50] != 200 || stats.ScorePercentiles[99] != 400 {
+		t.Errorf("Unexpected score distribution: %+v", stats)
+	}
+
+	expectedRates := []TileRate{
+		{Tile: 512, Games: 3, Rate: 0.75},
+		{Tile: 1024, Games: 2, Rate: 0.5},
+		{Tile: 2048, Games: 1, Rate: 0.25},
+	}
+	if !reflect.DeepEqual(stats.TileRates, expectedRates) {
+		t.Errorf("Expected tile rates %v, got %v", expectedRates, stats.TileRates)
+	}
+
+	if empty := Aggregate(nil, 0); empty.Games != 0 || empty.TileRates != nil {
+		t.Errorf("Unexpected stats without games: %+v", empty)
+	}
+}
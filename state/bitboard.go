@@ -0,0 +1,234 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Bitboard is an alternative representation of a 4x4 board, meant for
+// high-throughput simulations. Each cell is packed into 4 bits, holding
+// the exponent of its value, so 1 is a 2, 2 is a 4 and 0 is an empty cell.
+// Cell (row, column) lives at bit (row*4+column)*4.
+//
+// Moves are looked up in tables that hold the result of moving any row to
+// the left or right. Columns are handled by transposing the board. As 4
+// bits can't hold anything above 32768, two 32768 tiles never merge.
+//
+// Bitboard offers the same move, spawn and terminal API as Board. However,
+// Bitboard.Move doesn't report individual tile movements and merges.
+type Bitboard uint64
+
+const (
+	bitboardSize = 4
+	maxExponent  = 15
+)
+
+var (
+	// rowLeftTable and rowRightTable map a row to the row after moving
+	// it to the left or right respectively.
+	rowLeftTable  [1 << 16]uint16
+	rowRightTable [1 << 16]uint16
+	// rowLeftScoreTable and rowRightScoreTable hold the score gained by
+	// the respective move.
+	rowLeftScoreTable  [1 << 16]uint32
+	rowRightScoreTable [1 << 16]uint32
+)
+
+func init() {
+	for row := 0; row < 1<<16; row++ {
+		moved, score := moveRowLeft(uint16(row))
+		rowLeftTable[row] = moved
+		rowLeftScoreTable[row] = score
+
+		//Moving right equals mirroring, moving left and mirroring back.
+		reversed := reverseRow(uint16(row))
+		rowRightTable[reversed] = reverseRow(moved)
+		rowRightScoreTable[reversed] = score
+	}
+}
+
+// moveRowLeft slides and merges a single row, following the same rules
+// as Board.Move.
+func moveRowLeft(row uint16) (uint16, uint32) {
+	var line [bitboardSize]uint16
+	var placed int
+	var score uint32
+	mergeable := -1
+	for column := 0; column < bitboardSize; column++ {
+		exponent := (row >> (column * 4)) & 0xF
+		if exponent == 0 {
+			continue
+		}
+
+		if mergeable != -1 && line[mergeable] == exponent && exponent < maxExponent {
+			line[mergeable]++
+			score += 1 << line[mergeable]
+			mergeable = -1
+			continue
+		}
+
+		line[placed] = exponent
+		mergeable = placed
+		placed++
+	}
+
+	var moved uint16
+	for column, exponent := range line {
+		moved |= exponent << (column * 4)
+	}
+	return moved, score
+}
+
+func reverseRow(row uint16) uint16 {
+	return (row >> 12) | ((row >> 4) & 0x00F0) | ((row << 4) & 0x0F00) | (row << 12)
+}
+
+// NewBitboard converts a 4x4 board. An error is returned if the board has a
+// different size or holds values that can't be represented.
+func NewBitboard(board Board) (Bitboard, error) {
+	if board.Width() != bitboardSize || board.Height() != bitboardSize {
+		return 0, fmt.Errorf("only 4x4 boards are supported, but board is %dx%d", board.Width(), board.Height())
+	}
+
+	var bitboard Bitboard
+	for rowIndex, row := range board {
+		for cellIndex, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			if !isValidTileValue(cell) || cell > 1<<maxExponent {
+				return 0, errors.New("tiles have to be powers of two up to 32768")
+			}
+			bitboard = bitboard.Spawn(Position{Row: rowIndex, Column: cellIndex}, cell)
+		}
+	}
+	return bitboard, nil
+}
+
+// Board converts the bitboard into a regular board.
+func (bitboard Bitboard) Board() Board {
+	board := NewBoard(bitboardSize, bitboardSize)
+	for rowIndex := range board {
+		for cellIndex := range board[rowIndex] {
+			board[rowIndex][cellIndex] = bitboard.Cell(Position{Row: rowIndex, Column: cellIndex})
+		}
+	}
+	return board
+}
+
+func bitboardShift(position Position) uint {
+	return uint(position.Row*bitboardSize+position.Column) * 4
+}
+
+// Cell returns the value at the given position, zero meaning empty.
+func (bitboard Bitboard) Cell(position Position) uint {
+	exponent := (bitboard >> bitboardShift(position)) & 0xF
+	if exponent == 0 {
+		return 0
+	}
+	return 1 << exponent
+}
+
+// Spawn produces a bitboard with the given value placed at the given
+// position, no matter whether the cell was empty. The value has to be a
+// power of two up to 32768.
+func (bitboard Bitboard) Spawn(position Position, value uint) Bitboard {
+	shift := bitboardShift(position)
+	exponent := Bitboard(bits.TrailingZeros(value))
+	return (bitboard &^ (0xF << shift)) | exponent<<shift
+}
+
+// EmptyCells returns the positions of all empty cells in row-major order.
+func (bitboard Bitboard) EmptyCells() []Position {
+	var empty []Position
+	for index := 0; index < bitboardSize*bitboardSize; index++ {
+		if (bitboard>>(index*4))&0xF == 0 {
+			empty = append(empty, Position{Row: index / bitboardSize, Column: index % bitboardSize})
+		}
+	}
+	return empty
+}
+
+// MaxTile returns the highest value on the board.
+func (bitboard Bitboard) MaxTile() uint {
+	var max Bitboard
+	for ; bitboard != 0; bitboard >>= 4 {
+		if bitboard&0xF > max {
+			max = bitboard & 0xF
+		}
+	}
+	if max == 0 {
+		return 0
+	}
+	return 1 << max
+}
+
+// transpose mirrors the board along its main diagonal, turning columns
+// into rows.
+func (bitboard Bitboard) transpose() Bitboard {
+	a1 := bitboard & 0xF0F00F0FF0F00F0F
+	a2 := bitboard & 0x0000F0F00000F0F0
+	a3 := bitboard & 0x0F0F00000F0F0000
+	a := a1 | (a2 << 12) | (a3 >> 12)
+	b1 := a & 0xFF00FF0000FF00FF
+	b2 := a & 0x00FF00FF00000000
+	b3 := a & 0x00000000FF00FF00
+	return b1 | (b2 >> 24) | (b3 << 24)
+}
+
+// moveRows moves all rows either to the left or to the right.
+func (bitboard Bitboard) moveRows(left bool) (Bitboard, uint) {
+	var moved Bitboard
+	var score uint
+	for rowIndex := 0; rowIndex < bitboardSize; rowIndex++ {
+		shift := uint(rowIndex * 16)
+		row := uint16(bitboard >> shift)
+		if left {
+			moved |= Bitboard(rowLeftTable[row]) << shift
+			score += uint(rowLeftScoreTable[row])
+		} else {
+			moved |= Bitboard(rowRightTable[row]) << shift
+			score += uint(rowRightScoreTable[row])
+		}
+	}
+	return moved, score
+}
+
+// Move produces the bitboard after moving all tiles in the given
+// direction. The MoveResult only contains the direction, whether anything
+// changed and the score gained.
+func (bitboard Bitboard) Move(direction Direction) (Bitboard, MoveResult) {
+	result := MoveResult{Direction: direction}
+	var moved Bitboard
+	switch direction {
+	case Left, Right:
+		moved, result.ScoreGained = bitboard.moveRows(direction == Left)
+	case Up, Down:
+		//After transposing, up is left and down is right.
+		moved, result.ScoreGained = bitboard.transpose().moveRows(direction == Up)
+		moved = moved.transpose()
+	default:
+		moved = bitboard
+	}
+
+	result.Changed = moved != bitboard
+	return moved, result
+}
+
+// CanMove indicates whether moving in the given direction changes the
+// board.
+func (bitboard Bitboard) CanMove(direction Direction) bool {
+	moved, _ := bitboard.Move(direction)
+	return moved != bitboard
+}
+
+// IsTerminal indicates whether no move is possible anymore.
+func (bitboard Bitboard) IsTerminal() bool {
+	for _, direction := range Directions {
+		if bitboard.CanMove(direction) {
+			return false
+		}
+	}
+	return true
+}
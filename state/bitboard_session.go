@@ -0,0 +1,132 @@
+package state
+
+// BitboardSession plays a game like GameSession does, but on a Bitboard
+// as long as possible, which is a lot faster. Given the same rules, seed
+// and moves, it spawns the same tiles as a GameSession.
+//
+// There's no history, no notifications and the target tile is ignored, so
+// games go on until no move is possible. Once a 32768 tile has been
+// created, which a Bitboard can't merge, the game goes on on a regular
+// board.
+type BitboardSession struct {
+	// regular sets up the start tiles, provides the random number
+	// generator and takes over once the bitboard can't be used anymore.
+	regular  *GameSession
+	bitboard Bitboard
+	// fast is set while the bitboard is being played on.
+	fast bool
+	// fastMoves counts the moves played on the bitboard, as the regular
+	// session only counts moves it has logged.
+	fastMoves int
+}
+
+// NewBitboardSession produces a session played by the given rules. Rules
+// for boards other than 4x4 are played on a regular board. An error is
+// returned if the rules are invalid.
+func NewBitboardSession(rules Rules, seed int64) (*BitboardSession, error) {
+	rules.UndoLimit = 0
+	regular, err := NewGameSession(nil, rules, seed)
+	if err != nil {
+		return nil, err
+	}
+	regular.keepPlaying = true
+
+	session := &BitboardSession{regular: regular}
+	bitboard, err := NewBitboard(regular.GameBoard)
+	session.fast = err == nil && bitboard.MaxTile() < 1<<maxExponent
+	for _, entry := range rules.SpawnPolicy {
+		if entry.Value > 1<<maxExponent {
+			session.fast = false
+		}
+	}
+	session.bitboard = bitboard
+	return session, nil
+}
+
+// Move moves all tiles in the given direction and spawns a new tile if
+// anything changed. Like with Bitboard.Move, the MoveResult only contains
+// the direction, whether anything changed, the score gained and whether
+// the game is over.
+func (session *BitboardSession) Move(direction Direction) MoveResult {
+	regular := session.regular
+	if !session.fast {
+		result := regular.Move(direction)
+		return MoveResult{
+			Direction:   direction,
+			Changed:     result.Changed,
+			ScoreGained: result.ScoreGained,
+			GameOver:    result.GameOver,
+		}
+	}
+
+	moved, result := session.bitboard.Move(direction)
+	if result.Changed {
+		regular.score += result.ScoreGained
+		session.fastMoves++
+		session.bitboard = session.spawn(moved)
+		regular.GameOver = session.bitboard.IsTerminal()
+
+		if session.bitboard.MaxTile() >= 1<<maxExponent {
+			regular.GameBoard = session.bitboard.Board()
+			session.fast = false
+		}
+	}
+	result.GameOver = regular.GameOver
+	return result
+}
+
+// spawn places a new tile the same way GameSession.fillCell does, without
+// allocating the list of empty cells.
+func (session *BitboardSession) spawn(bitboard Bitboard) Bitboard {
+	var empty int
+	for index := 0; index < bitboardSize*bitboardSize; index++ {
+		if (bitboard>>(index*4))&0xF == 0 {
+			empty++
+		}
+	}
+
+	random := session.regular.random
+	chosen := random.Intn(empty)
+	for index := 0; index < bitboardSize*bitboardSize; index++ {
+		if (bitboard>>(index*4))&0xF != 0 {
+			continue
+		}
+		if chosen == 0 {
+			position := Position{Row: index / bitboardSize, Column: index % bitboardSize}
+			return bitboard.Spawn(position, session.regular.rules.SpawnPolicy.pick(random.Float64()))
+		}
+		chosen--
+	}
+	return bitboard
+}
+
+// Board returns a copy of the current board.
+func (session *BitboardSession) Board() Board {
+	if session.fast {
+		return session.bitboard.Board()
+	}
+	return session.regular.GameBoard.Copy()
+}
+
+// MaxTile returns the highest value on the board.
+func (session *BitboardSession) MaxTile() uint {
+	if session.fast {
+		return session.bitboard.MaxTile()
+	}
+	return session.regular.GameBoard.MaxTile()
+}
+
+// Score returns the sum of all tiles created by merges.
+func (session *BitboardSession) Score() uint {
+	return session.regular.score
+}
+
+// MoveCount returns the amount of moves that changed the board.
+func (session *BitboardSession) MoveCount() int {
+	return session.fastMoves + session.regular.moveCount
+}
+
+// GameOver indicates whether no move is possible anymore.
+func (session *BitboardSession) GameOver() bool {
+	return session.regular.GameOver
+}
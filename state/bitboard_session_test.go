@@ -0,0 +1,76 @@
+package state
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// playBoth plays the same random moves on a GameSession and a
+// BitboardSession and fails as soon as they differ.
+func playBoth(t *testing.T, rules Rules, seed int64) *BitboardSession {
+	regular, err := NewGameSession(nil, rules, seed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	fast, err := NewBitboardSession(rules, seed)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	random := rand.New(rand.NewSource(seed))
+	for !regular.GameOver {
+		regular.KeepPlaying()
+		direction := Directions[random.Intn(len(Directions))]
+		regularResult := regular.Move(direction)
+		fastResult := fast.Move(direction)
+		if regularResult.Changed != fastResult.Changed || regularResult.ScoreGained != fastResult.ScoreGained ||
+			!reflect.DeepEqual(regular.GameBoard, fast.Board()) {
+			t.Fatalf("Sessions diverged after %d moves moving %s:\n%s\n\n%s",
+				regular.MoveCount(), direction, formatBoard(regular.GameBoard), formatBoard(fast.Board()))
+		}
+	}
+
+	if !fast.GameOver() || fast.Score() != regular.Score() ||
+		fast.MoveCount() != regular.MoveCount() || fast.MaxTile() != regular.GameBoard.MaxTile() {
+		t.Fatalf("Expected same final state, got score %d and %d moves instead of score %d and %d moves",
+			fast.Score(), fast.MoveCount(), regular.Score(), regular.MoveCount())
+	}
+	return fast
+}
+
+func TestBitboardSession_MatchesGameSession(t *testing.T) {
+	for seed := int64(0); seed < 20; seed++ {
+		playBoth(t, DefaultRules(), seed)
+	}
+
+	rules := DefaultRules()
+	rules.Width = 5
+	if session := playBoth(t, rules, 1); session.fast {
+		t.Error("Expected 5x4 board to be played on a regular board")
+	}
+}
+
+func TestBitboardSession_LargeTiles(t *testing.T) {
+	//Two 16384 tiles merge into a tile the bitboard can't merge anymore.
+	rules := DefaultRules()
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 16384},
+		{Position: Position{Row: 0, Column: 1}, Value: 16384},
+		{Position: Position{Row: 1, Column: 0}, Value: 32},
+	}
+	var reached int
+	for seed := int64(0); seed < 5; seed++ {
+		session := playBoth(t, rules, seed)
+		if session.MaxTile() < 32768 {
+			continue
+		}
+		reached++
+		if session.fast {
+			t.Error("Expected session to continue on a regular board after creating 32768")
+		}
+	}
+	if reached == 0 {
+		t.Error("Expected at least one game to create a 32768 tile")
+	}
+}
@@ -0,0 +1,118 @@
+package state
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBitboard_Conversion(t *testing.T) {
+	board := Board{
+		{2, 4, 8, 16},
+		{32, 64, 128, 256},
+		{512, 1024, 2048, 4096},
+		{8192, 16384, 32768, 0},
+	}
+	bitboard, err := NewBitboard(board)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(bitboard.Board(), board) {
+		t.Fatalf("Incorrect board after conversion:\n%s", formatBoard(bitboard.Board()))
+	}
+	if bitboard.MaxTile() != 32768 {
+		t.Errorf("Expected max tile 32768, got %d", bitboard.MaxTile())
+	}
+	if empty := bitboard.EmptyCells(); !reflect.DeepEqual(empty, []Position{{Row: 3, Column: 3}}) {
+		t.Errorf("Expected only the last cell to be empty, got %v", empty)
+	}
+
+	if _, err := NewBitboard(NewBoard(5, 5)); err == nil {
+		t.Error("Expected error for 5x5 board")
+	}
+	if _, err := NewBitboard(NewBoard(4, 4).Spawn(Position{}, 65536)); err == nil {
+		t.Error("Expected error for tile above 32768")
+	}
+}
+
+func TestBitboard_transpose(t *testing.T) {
+	board := Board{
+		{2, 4, 8, 16},
+		{32, 64, 128, 256},
+		{512, 1024, 2048, 4096},
+		{8192, 16384, 32768, 0},
+	}
+	bitboard, _ := NewBitboard(board)
+	transposed := bitboard.transpose().Board()
+	for rowIndex, row := range board {
+		for cellIndex, cell := range row {
+			if transposed[cellIndex][rowIndex] != cell {
+				t.Fatalf("Incorrect transposition:\n%s", formatBoard(transposed))
+			}
+		}
+	}
+}
+
+// TestBitboard_MatchesBoard plays random games on both engines and
+// expects them to behave exactly the same.
+func TestBitboard_MatchesBoard(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	for game := 0; game < 50; game++ {
+		board := NewBoard(4, 4).Spawn(Position{Row: random.Intn(4), Column: random.Intn(4)}, 2)
+		bitboard, _ := NewBitboard(board)
+		for !board.IsTerminal() {
+			if bitboard.IsTerminal() {
+				t.Fatalf("Bitboard is terminal, but board isn't:\n%s", formatBoard(board))
+			}
+
+			direction := Directions[random.Intn(len(Directions))]
+			movedBoard, boardResult := board.Move(direction)
+			movedBitboard, bitboardResult := bitboard.Move(direction)
+			if !reflect.DeepEqual(movedBitboard.Board(), movedBoard) ||
+				boardResult.Changed != bitboardResult.Changed ||
+				boardResult.ScoreGained != bitboardResult.ScoreGained {
+				t.Fatalf("Engines diverged moving %s:\n%s\n\n%s",
+					direction, formatBoard(movedBoard), formatBoard(movedBitboard.Board()))
+			}
+			if !boardResult.Changed {
+				continue
+			}
+
+			empty := movedBoard.EmptyCells()
+			if !reflect.DeepEqual(empty, movedBitboard.EmptyCells()) {
+				t.Fatalf("Empty cells differ:\n%s", formatBoard(movedBoard))
+			}
+			position := empty[random.Intn(len(empty))]
+			board = movedBoard.Spawn(position, 2)
+			bitboard = movedBitboard.Spawn(position, 2)
+		}
+		if !bitboard.IsTerminal() {
+			t.Fatalf("Board is terminal, but bitboard isn't:\n%s", formatBoard(board))
+		}
+	}
+}
+
+var benchmarkBoard = Board{
+	{2, 4, 8, 16},
+	{4, 0, 2, 2},
+	{0, 2, 0, 4},
+	{128, 64, 32, 8},
+}
+
+func BenchmarkBoard_Move(b *testing.B) {
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		benchmarkBoard.Move(Directions[i%len(Directions)])
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "moves/s")
+}
+
+func BenchmarkBitboard_Move(b *testing.B) {
+	bitboard, _ := NewBitboard(benchmarkBoard)
+	start := time.Now()
+	for i := 0; i < b.N; i++ {
+		bitboard.Move(Directions[i%len(Directions)])
+	}
+	b.ReportMetric(float64(b.N)/time.Since(start).Seconds(), "moves/s")
+}
@@ -0,0 +1,293 @@
+package state
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Board is a grid of tiles, indexed by row first and by column second,
+// where zero marks an empty cell. All rows have the same length.
+//
+// Board is meant to be used as a value. None of its methods modify the
+// board they are called on, instead they produce a new board. This makes
+// it safe to use for simulations, for example by solvers.
+type Board [][]uint
+
+// NewBoard creates an empty board with the given amount of columns
+// (width) and rows (height).
+func NewBoard(width, height int) Board {
+	board := make(Board, height)
+	for rowIndex := range board {
+		board[rowIndex] = make([]uint, width)
+	}
+	return board
+}
+
+// Width returns the amount of columns of the board.
+func (board Board) Width() int {
+	if len(board) == 0 {
+		return 0
+	}
+	return len(board[0])
+}
+
+// Height returns the amount of rows of the board.
+func (board Board) Height() int {
+	return len(board)
+}
+
+// Copy produces a deep copy of the board.
+func (board Board) Copy() Board {
+	boardCopy := make(Board, len(board))
+	for rowIndex, row := range board {
+		boardCopy[rowIndex] = append([]uint(nil), row...)
+	}
+	return boardCopy
+}
+
+// Format encodes the board in a compact text form, which is used by
+// replays and engines. Rows are separated by '/' and the cells of a row by
+// ',', with 0 marking empty cells, for example "2,0/0,4".
+func (board Board) Format() string {
+	rows := make([]string, 0, len(board))
+	for _, row := range board {
+		cells := make([]string, 0, len(row))
+		for _, cell := range row {
+			cells = append(cells, strconv.FormatUint(uint64(cell), 10))
+		}
+		rows = append(rows, strings.Join(cells, ","))
+	}
+	return strings.Join(rows, "/")
+}
+
+// ParseBoard decodes a board encoded by Board.Format. The tile values
+// aren't validated.
+func ParseBoard(value string) (Board, error) {
+	var board Board
+	for _, row := range strings.Split(value, "/") {
+		cells := strings.Split(row, ",")
+		if len(board) > 0 && len(cells) != len(board[0]) {
+			return nil, fmt.Errorf("board %q has rows of different length", value)
+		}
+
+		boardRow := make([]uint, 0, len(cells))
+		for _, cell := range cells {
+			//Tiles are as large as a uint, so everything Format writes
+			//can be read back.
+			cellValue, err := strconv.ParseUint(cell, 10, strconv.IntSize)
+			if err != nil {
+				return nil, fmt.Errorf("board %q contains invalid cell %q", value, cell)
+			}
+			boardRow = append(boardRow, uint(cellValue))
+		}
+		board = append(board, boardRow)
+	}
+	return board, nil
+}
+
+// EmptyCells returns the positions of all empty cells in row-major order.
+func (board Board) EmptyCells() []Position {
+	var empty []Position
+	for rowIndex, row := range board {
+		for cellIndex, cell := range row {
+			if cell == 0 {
+				empty = append(empty, Position{Row: rowIndex, Column: cellIndex})
+			}
+		}
+	}
+	return empty
+}
+
+// MaxTile returns the highest value on the board.
+func (board Board) MaxTile() uint {
+	var max uint
+	for _, row := range board {
+		for _, cell := range row {
+			if cell > max {
+				max = cell
+			}
+		}
+	}
+	return max
+}
+
+// Sum returns the sum of all tiles on the board.
+func (board Board) Sum() uint {
+	var sum uint
+	for _, row := range board {
+		for _, cell := range row {
+			sum += cell
+		}
+	}
+	return sum
+}
+
+// Spawn produces a copy of the board with the given value placed at the
+// given position, no matter whether the cell was empty.
+func (board Board) Spawn(position Position, value uint) Board {
+	boardCopy := board.Copy()
+	boardCopy[position.Row][position.Column] = value
+	return boardCopy
+}
+
+// IsTerminal indicates whether no move is possible anymore.
+func (board Board) IsTerminal() bool {
+	for rowIndex, row := range board {
+		for cellIndex, cell := range row {
+			if cell == 0 {
+				return false
+			}
+			//Comparing with the right and lower neighbour covers all pairs.
+			if cellIndex+1 < len(row) && row[cellIndex+1] == cell {
+				return false
+			}
+			if rowIndex+1 < len(board) && board[rowIndex+1][cellIndex] == cell {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// CanMove indicates whether moving in the given direction changes the
+// board.
+func (board Board) CanMove(direction Direction) bool {
+	for _, line := range board.lines(direction) {
+		for index := 1; index < len(line); index++ {
+			cell := board[line[index].Row][line[index].Column]
+			if cell == 0 {
+				continue
+			}
+
+			previous := board[line[index-1].Row][line[index-1].Column]
+			if previous == 0 || previous == cell {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Move produces the board after moving all tiles in the given direction,
+// as well as a description of the move. No tile is spawned, that is up to
+// the caller. If nothing changed, the returned board equals the original.
+func (board Board) Move(direction Direction) (Board, MoveResult) {
+	result := MoveResult{Direction: direction}
+	moved := NewBoard(board.Width(), board.Height())
+	for _, line := range board.lines(direction) {
+		board.moveLine(line, moved, &result)
+	}
+
+	//Lines are processed in different orders depending on the direction,
+	//but results should be consistent, so we use row-major order.
+	sort.Slice(result.Moves, func(a, b int) bool {
+		return isBefore(result.Moves[a].To, result.Moves[b].To)
+	})
+	sort.Slice(result.Merges, func(a, b int) bool {
+		return isBefore(result.Merges[a].To, result.Merges[b].To)
+	})
+
+	return moved, result
+}
+
+// lines returns the positions of all rows or columns that are affected by
+// a move in the given direction. Each line starts at the edge the tiles
+// are moving towards.
+func (board Board) lines(direction Direction) [][]Position {
+	width, height := board.Width(), board.Height()
+	var lines [][]Position
+	switch direction {
+	case Up, Down:
+		for cellIndex := 0; cellIndex < width; cellIndex++ {
+			line := make([]Position, height)
+			for index := range line {
+				rowIndex := index
+				if direction == Down {
+					rowIndex = height - 1 - index
+				}
+				line[index] = Position{Row: rowIndex, Column: cellIndex}
+			}
+			lines = append(lines, line)
+		}
+	case Left, Right:
+		for rowIndex := 0; rowIndex < height; rowIndex++ {
+			line := make([]Position, width)
+			for index := range line {
+				cellIndex := index
+				if direction == Right {
+					cellIndex = width - 1 - index
+				}
+				line[index] = Position{Row: rowIndex, Column: cellIndex}
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// moveLine slides and merges the tiles of a single line into target.
+// Tiles are merged starting at the edge, as the original game does it.
+// So 2,2,2,0 becomes 4,2,0,0 and not 2,4,0,0. A tile can only be merged
+// once per move, so 2,2,4,0 becomes 4,4,0,0.
+func (board Board) moveLine(line []Position, target Board, result *MoveResult) {
+	//origins holds the original position of the tile placed at the same
+	//index of the line. mergedWith is only set for merged tiles.
+	origins := make([]Position, 0, len(line))
+	mergedWith := make([]*Position, 0, len(line))
+	//mergeable is the index of the last placed tile, if it hasn't been
+	//merged yet.
+	mergeable := -1
+	for _, position := range line {
+		cell := board[position.Row][position.Column]
+		if cell == 0 {
+			continue
+		}
+
+		if mergeable != -1 {
+			destination := line[mergeable]
+			if target[destination.Row][destination.Column] == cell {
+				target[destination.Row][destination.Column] = cell * 2
+				from := position
+				mergedWith[mergeable] = &from
+				mergeable = -1
+				continue
+			}
+		}
+
+		destination := line[len(origins)]
+		target[destination.Row][destination.Column] = cell
+		mergeable = len(origins)
+		origins = append(origins, position)
+		mergedWith = append(mergedWith, nil)
+	}
+
+	for index, origin := range origins {
+		destination := line[index]
+		value := target[destination.Row][destination.Column]
+		if mergedWith[index] != nil {
+			result.Merges = append(result.Merges, TileMerge{
+				From:  [2]Position{origin, *mergedWith[index]},
+				To:    destination,
+				Value: value,
+			})
+			result.ScoreGained += value
+			result.Changed = true
+		} else if origin != destination {
+			result.Moves = append(result.Moves, TileMove{
+				From:  origin,
+				To:    destination,
+				Value: value,
+			})
+			result.Changed = true
+		}
+	}
+}
+
+func isBefore(a, b Position) bool {
+	if a.Row != b.Row {
+		return a.Row < b.Row
+	}
+	return a.Column < b.Column
+}
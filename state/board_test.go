@@ -0,0 +1,75 @@
+package state
+
+import (
+	"reflect"
+	"strconv"
+	"testing"
+)
+
+func TestBoard_EmptyCellsAndSpawn(t *testing.T) {
+	board := Board{
+		{2, 0},
+		{0, 4},
+	}
+
+	expected := []Position{{Row: 0, Column: 1}, {Row: 1, Column: 0}}
+	if empty := board.EmptyCells(); !reflect.DeepEqual(empty, expected) {
+		t.Fatalf("Expected empty cells %v, got %v", expected, empty)
+	}
+
+	spawned := board.Spawn(Position{Row: 1, Column: 0}, 8)
+	if board[1][0] != 0 {
+		t.Fatalf("Spawn modified the original board:\n%s", formatBoard(board))
+	}
+	if spawned[1][0] != 8 || len(spawned.EmptyCells()) != 1 {
+		t.Fatalf("Incorrect board after spawning:\n%s", formatBoard(spawned))
+	}
+	if spawned.MaxTile() != 8 || spawned.Sum() != 14 {
+		t.Errorf("Expected max tile 8 and sum 14, got %d and %d", spawned.MaxTile(), spawned.Sum())
+	}
+}
+
+func TestBoard_CanMove(t *testing.T) {
+	board := Board{
+		{2, 4, 8},
+		{0, 0, 16},
+		{0, 0, 32},
+	}
+
+	expected := map[Direction]bool{Up: false, Down: true, Left: true, Right: false}
+	for direction, canMove := range expected {
+		if board.CanMove(direction) != canMove {
+			t.Errorf("Expected CanMove(%s) to be %v", direction, canMove)
+		}
+	}
+}
+
+func TestBoard_FormatAndParse(t *testing.T) {
+	board := Board{
+		{2, 0, 1024},
+		{0, 4, 0},
+	}
+	if formatted := board.Format(); formatted != "2,0,1024/0,4,0" {
+		t.Fatalf("Unexpected format %q", formatted)
+	}
+	parsed, err := ParseBoard(board.Format())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !reflect.DeepEqual(parsed, board) {
+		t.Errorf("Expected %v after parsing, got %v", board, parsed)
+	}
+
+	//Shifting by a variable, so that this compiles on 32 bit platforms.
+	shift := strconv.IntSize - 1
+	large := Board{{1 << shift, 0}, {0, 2}}
+	if parsed, err := ParseBoard(large.Format()); err != nil || !reflect.DeepEqual(parsed, large) {
+		t.Errorf("Expected large tiles to be parsed, got %v (%v)", parsed, err)
+	}
+
+	for _, invalid := range []string{"2,0/4", "2,x/0,0", ""} {
+		if _, err := ParseBoard(invalid); err == nil {
+			t.Errorf("Expected error for %q", invalid)
+		}
+	}
+}
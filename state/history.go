@@ -0,0 +1,101 @@
+package state
+
+// SessionState is everything required to restore a session to the state
+// at a certain point, including the state of the random source, so that
+// redoing or repeating a move spawns the same tile.
+type SessionState struct {
+	Board       Board  `json:"board"`
+	Score       uint   `json:"score"`
+	MoveCount   int    `json:"moveCount"`
+	RandomState uint64 `json:"randomState"`
+	GameOver    bool   `json:"gameOver"`
+	Won         bool   `json:"won"`
+	KeepPlaying bool   `json:"keepPlaying"`
+}
+
+func (session *GameSession) snapshot() SessionState {
+	return SessionState{
+		Board:       session.GameBoard.Copy(),
+		Score:       session.score,
+		MoveCount:   session.moveCount,
+		RandomState: session.source.state,
+		GameOver:    session.GameOver,
+		Won:         session.Won,
+		KeepPlaying: session.keepPlaying,
+	}
+}
+
+func (session *GameSession) restore(snapshot SessionState) {
+	session.GameBoard = snapshot.Board.Copy()
+	session.score = snapshot.Score
+	session.moveCount = snapshot.MoveCount
+	session.source.state = snapshot.RandomState
+	session.GameOver = snapshot.GameOver
+	//A win is only reported once per game, so undoing the winning move
+	//mustn't take it back. Undoing while the win is being announced
+	//counts as deciding to keep playing.
+	session.keepPlaying = session.keepPlaying || snapshot.KeepPlaying || session.Won
+	session.Won = session.Won || snapshot.Won
+}
+
+// record pushes the state before a move onto the undo stack. Making a new
+// move invalidates everything that could've been redone.
+func (session *GameSession) record(before SessionState) {
+	session.redoStack = nil
+
+	limit := session.rules.UndoLimit
+	if limit == 0 {
+		return
+	}
+
+	session.undoStack = append(session.undoStack, before)
+	if limit > 0 && len(session.undoStack) > limit {
+		session.undoStack = session.undoStack[len(session.undoStack)-limit:]
+	}
+}
+
+// CanUndo indicates whether there's at least one move that can be undone.
+func (session *GameSession) CanUndo() bool {
+	return len(session.undoStack) > 0
+}
+
+// CanRedo indicates whether there's at least one undone move that can be
+// redone.
+func (session *GameSession) CanRedo() bool {
+	return len(session.redoStack) > 0
+}
+
+// Undo reverts the last move, including the tile spawned by it. False is
+// returned if there's nothing to undo.
+func (session *GameSession) Undo() bool {
+	if !session.CanUndo() {
+		return false
+	}
+
+	session.redoStack = append(session.redoStack, session.snapshot())
+	session.restore(session.undoStack[len(session.undoStack)-1])
+	session.undoStack = session.undoStack[:len(session.undoStack)-1]
+	session.undoCount++
+	session.notify()
+	return true
+}
+
+// Redo repeats the last undone move, which will spawn the same tile as
+// before. False is returned if there's nothing to redo.
+func (session *GameSession) Redo() bool {
+	if !session.CanRedo() {
+		return false
+	}
+
+	session.undoStack = append(session.undoStack, session.snapshot())
+	session.restore(session.redoStack[len(session.redoStack)-1])
+	session.redoStack = session.redoStack[:len(session.redoStack)-1]
+	session.notify()
+	return true
+}
+
+// UndoCount returns how often Undo has been used in this session. Scores
+// of sessions with undos aren't comparable to scores of regular sessions.
+func (session *GameSession) UndoCount() int {
+	return session.undoCount
+}
@@ -0,0 +1,193 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGameSession_UndoRedo(t *testing.T) {
+	rules := DefaultRules()
+	rules.UndoLimit = -1
+	session, err := NewGameSession(nil, rules, 7)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if session.Undo() || session.Redo() {
+		t.Fatal("Expected nothing to undo or redo on a new session")
+	}
+
+	var boards []Board
+	var scores []uint
+	moves := []func() MoveResult{session.Left, session.Up, session.Right, session.Down}
+	for index := 0; len(boards) < 10; index++ {
+		boards = append(boards, session.GameBoard.Copy())
+		scores = append(scores, session.Score())
+		moves[index%len(moves)]()
+		//Ignore moves that didn't change anything.
+		if reflect.DeepEqual(boards[len(boards)-1], session.GameBoard) {
+			boards = boards[:len(boards)-1]
+			scores = scores[:len(scores)-1]
+		}
+	}
+
+	finalBoard := session.GameBoard.Copy()
+	finalScore := session.Score()
+	for index := len(boards) - 1; index >= 0; index-- {
+		if !session.Undo() {
+			t.Fatalf("Expected undo %d to be possible", len(boards)-index)
+		}
+		if !reflect.DeepEqual(session.GameBoard, boards[index]) || session.Score() != scores[index] {
+			t.Fatalf("Incorrect board after undo:\nExpected:\n%s\nActual:  \n%s",
+				formatBoard(boards[index]), formatBoard(session.GameBoard))
+		}
+	}
+	if session.UndoCount() != len(boards) {
+		t.Errorf("Expected %d undos to be counted, got %d", len(boards), session.UndoCount())
+	}
+
+	for session.Redo() {
+	}
+	if !reflect.DeepEqual(session.GameBoard, finalBoard) || session.Score() != finalScore {
+		t.Fatalf("Incorrect board after redoing everything:\nExpected:\n%s\nActual:  \n%s",
+			formatBoard(finalBoard), formatBoard(session.GameBoard))
+	}
+}
+
+func TestGameSession_UndoRestoresRandomness(t *testing.T) {
+	rules := DefaultRules()
+	rules.UndoLimit = 1
+	session, err := NewGameSession(nil, rules, 3)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	moves := []func() MoveResult{session.Left, session.Up, session.Right, session.Down}
+	for _, move := range moves {
+		before := session.GameBoard.Copy()
+		move()
+		if reflect.DeepEqual(before, session.GameBoard) {
+			continue
+		}
+
+		after := session.GameBoard.Copy()
+		session.Undo()
+		move()
+		if !reflect.DeepEqual(after, session.GameBoard) {
+			t.Fatalf("Expected repeated move to spawn the same tile:\nExpected:\n%s\nActual:  \n%s",
+				formatBoard(after), formatBoard(session.GameBoard))
+		}
+		return
+	}
+	t.Fatal("Expected at least one move to be possible")
+}
+
+func TestGameSession_UndoLimit(t *testing.T) {
+	tests := []struct {
+		limit         int
+		expectedUndos int
+	}{
+		{limit: 0, expectedUndos: 0},
+		{limit: 2, expectedUndos: 2},
+		{limit: -1, expectedUndos: 4},
+	}
+
+	for _, test := range tests {
+		rules := DefaultRules()
+		rules.UndoLimit = test.limit
+		session, err := NewGameSession(nil, rules, 1)
+		if err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+
+		var changes int
+		moves := []func() MoveResult{session.Left, session.Up, session.Right, session.Down}
+		for index := 0; changes < 4; index++ {
+			before := session.GameBoard.Copy()
+			moves[index%len(moves)]()
+			if !reflect.DeepEqual(before, session.GameBoard) {
+				changes++
+			}
+		}
+
+		var undos int
+		for session.Undo() {
+			undos++
+		}
+		if undos != test.expectedUndos {
+			t.Errorf("Expected %d undos with limit %d, got %d", test.expectedUndos, test.limit, undos)
+		}
+	}
+}
+
+func TestGameSession_Log(t *testing.T) {
+	rules := DefaultRules()
+	rules.UndoLimit = -1
+	session, err := NewGameSession(nil, rules, 11)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for index := 0; index < 30; index++ {
+		session.Move(Directions[index%len(Directions)])
+	}
+	session.Undo()
+	session.Undo()
+	session.Redo()
+
+	log := session.Log()
+	if len(log) != session.MoveCount() {
+		t.Fatalf("Expected log of %d moves, got %d", session.MoveCount(), len(log))
+	}
+
+	//Replaying the log on the start tiles has to reproduce the game.
+	board := NewBoard(rules.Width, rules.Height)
+	for _, tile := range session.StartTiles() {
+		board = board.Spawn(tile.Position, tile.Value)
+	}
+	for _, move := range log {
+		board, _ = board.Move(move.Direction)
+		if move.Spawn != nil {
+			board = board.Spawn(move.Spawn.Position, move.Spawn.Value)
+		}
+	}
+	if !reflect.DeepEqual(board, session.GameBoard) {
+		t.Fatalf("Replayed log differs:\nExpected:\n%s\nActual:  \n%s",
+			formatBoard(session.GameBoard), formatBoard(board))
+	}
+}
+
+func TestGameSession_UndoKeepsWin(t *testing.T) {
+	rules := DefaultRules()
+	rules.TargetTile = 16
+	rules.UndoLimit = -1
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 8},
+		{Position: Position{Row: 0, Column: 1}, Value: 8},
+	}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	session.Right()
+	if !session.Won || !session.AwaitingDecision() {
+		t.Fatal("Expected game to be won and awaiting a decision")
+	}
+
+	session.Undo()
+	if !session.Won || session.AwaitingDecision() {
+		t.Fatal("Expected undo to keep the win, but not await a decision anymore")
+	}
+
+	session.Right()
+	if session.AwaitingDecision() {
+		t.Fatal("Expected win to not be reported again after merging again")
+	}
+
+	session.Undo()
+	session.Redo()
+	if !session.Won || session.AwaitingDecision() {
+		t.Fatal("Expected redo to keep the win without awaiting a decision")
+	}
+}
@@ -0,0 +1,99 @@
+package state
+
+import "fmt"
+
+// Direction is the direction all tiles are moved in.
+type Direction int
+
+const (
+	Up Direction = iota
+	Down
+	Left
+	Right
+)
+
+// Directions contains all possible directions.
+var Directions = [...]Direction{Up, Down, Left, Right}
+
+func (direction Direction) String() string {
+	switch direction {
+	case Up:
+		return "up"
+	case Down:
+		return "down"
+	case Left:
+		return "left"
+	case Right:
+		return "right"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseDirection is the inverse of Direction.String.
+func ParseDirection(value string) (Direction, error) {
+	for _, direction := range Directions {
+		if direction.String() == value {
+			return direction, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown direction %q", value)
+}
+
+// MarshalText encodes the direction by its name, keeping serialized
+// formats readable.
+func (direction Direction) MarshalText() ([]byte, error) {
+	return []byte(direction.String()), nil
+}
+
+// UnmarshalText decodes a direction encoded by MarshalText.
+func (direction *Direction) UnmarshalText(text []byte) error {
+	parsed, err := ParseDirection(string(text))
+	if err != nil {
+		return err
+	}
+	*direction = parsed
+	return nil
+}
+
+// LoggedMove is a move that changed the board, along with the tile that
+// has been spawned afterwards. Replaying logged moves reproduces a game
+// without depending on the random source.
+type LoggedMove struct {
+	Direction Direction `json:"direction"`
+	Spawn     *Tile     `json:"spawn,omitempty"`
+}
+
+// MoveResult describes everything that happened during a single move.
+type MoveResult struct {
+	Direction Direction `json:"direction"`
+	// Changed is false if no tile has moved or merged. In that case, the
+	// move wasn't valid and nothing has been spawned.
+	Changed bool `json:"changed"`
+	// Moves contains all tiles that moved without merging.
+	Moves []TileMove `json:"moves"`
+	// Merges contains all pairs of tiles that merged into a new tile.
+	Merges []TileMerge `json:"merges"`
+	// Spawn is the tile placed after the move, if any.
+	Spawn *Tile `json:"spawn"`
+	// ScoreGained is the sum of the values of all merged tiles.
+	ScoreGained uint `json:"scoreGained"`
+	// GameOver indicates whether the move ended the game.
+	GameOver bool `json:"gameOver"`
+}
+
+// TileMove is a tile that moved from one cell to another.
+type TileMove struct {
+	From  Position `json:"from"`
+	To    Position `json:"to"`
+	Value uint     `json:"value"`
+}
+
+// TileMerge are two tiles that moved to the same cell and merged. The
+// first position is the tile that was closer to the edge the tiles were
+// moved towards.
+type TileMerge struct {
+	From  [2]Position `json:"from"`
+	To    Position    `json:"to"`
+	Value uint        `json:"value"`
+}
@@ -0,0 +1,115 @@
+package state
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestMoveResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    Board
+		move     Direction
+		expected MoveResult
+	}{
+		{
+			name: "left",
+			board: Board{
+				{2, 2, 2, 0},
+				{0, 0, 0, 8},
+				{4, 0, 0, 0},
+				{0, 0, 0, 0},
+			},
+			move: Left,
+			expected: MoveResult{
+				Direction: Left,
+				Changed:   true,
+				Moves: []TileMove{
+					{From: Position{Row: 0, Column: 2}, To: Position{Row: 0, Column: 1}, Value: 2},
+					{From: Position{Row: 1, Column: 3}, To: Position{Row: 1, Column: 0}, Value: 8},
+				},
+				Merges: []TileMerge{
+					{From: [2]Position{{Row: 0, Column: 0}, {Row: 0, Column: 1}}, To: Position{Row: 0, Column: 0}, Value: 4},
+				},
+				ScoreGained: 4,
+			},
+		},
+		{
+			name: "right",
+			board: Board{
+				{2, 2, 2, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+			},
+			move: Right,
+			expected: MoveResult{
+				Direction: Right,
+				Changed:   true,
+				Moves: []TileMove{
+					{From: Position{Row: 0, Column: 0}, To: Position{Row: 0, Column: 2}, Value: 2},
+				},
+				Merges: []TileMerge{
+					{From: [2]Position{{Row: 0, Column: 2}, {Row: 0, Column: 1}}, To: Position{Row: 0, Column: 3}, Value: 4},
+				},
+				ScoreGained: 4,
+			},
+		},
+		{
+			name: "down with two merges",
+			board: Board{
+				{4, 0, 0, 0},
+				{4, 0, 0, 0},
+				{8, 0, 0, 0},
+				{8, 0, 0, 0},
+			},
+			move: Down,
+			expected: MoveResult{
+				Direction: Down,
+				Changed:   true,
+				Merges: []TileMerge{
+					{From: [2]Position{{Row: 1, Column: 0}, {Row: 0, Column: 0}}, To: Position{Row: 2, Column: 0}, Value: 8},
+					{From: [2]Position{{Row: 3, Column: 0}, {Row: 2, Column: 0}}, To: Position{Row: 3, Column: 0}, Value: 16},
+				},
+				ScoreGained: 24,
+			},
+		},
+		{
+			name: "up without change",
+			board: Board{
+				{4, 0, 0, 0},
+				{8, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+			},
+			move:     Up,
+			expected: MoveResult{Direction: Up},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, result := test.board.Move(test.move)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("Incorrect result:\nExpected: %+v\nActual:   %+v", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestMoveResult_Spawn(t *testing.T) {
+	rules := DefaultRules()
+	rules.InitialTiles = []Tile{{Position: Position{Row: 0, Column: 3}, Value: 2}}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	result := session.Left()
+	if !result.Changed || result.Spawn == nil {
+		t.Fatalf("Expected move to change board and spawn a tile, got %+v", result)
+	}
+	if cell := session.GameBoard[result.Spawn.Row][result.Spawn.Column]; cell != result.Spawn.Value {
+		t.Errorf("Expected spawned tile %d on board, got %d", result.Spawn.Value, cell)
+	}
+}
@@ -0,0 +1,36 @@
+package state
+
+import "time"
+
+// source is a splitmix64 based rand.Source64. Unlike the sources provided
+// by math/rand, its complete state is a single number, so it can be read
+// and restored, which makes sessions reproducible.
+type source struct {
+	state uint64
+}
+
+func newSource(seed int64) *source {
+	return &source{state: uint64(seed)}
+}
+
+func (source *source) Seed(seed int64) {
+	source.state = uint64(seed)
+}
+
+func (source *source) Uint64() uint64 {
+	source.state += 0x9e3779b97f4a7c15
+	z := source.state
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+func (source *source) Int63() int64 {
+	return int64(source.Uint64() >> 1)
+}
+
+// RandomSeed produces a seed that differs between calls, for sessions that
+// don't need to be reproduced on purpose.
+func RandomSeed() int64 {
+	return time.Now().UnixNano()
+}
@@ -0,0 +1,231 @@
+package state
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+)
+
+const (
+	// MinSize is the minimum width and height of a board.
+	MinSize = 2
+	// MaxSize is the maximum width and height of a board. It keeps boards
+	// small enough to be shown on a terminal and prevents huge
+	// allocations for boards requested over the network.
+	MaxSize = 16
+)
+
+// Rules describes the configuration a GameSession is played with. Rules
+// are fixed for the lifetime of a session.
+type Rules struct {
+	// Width is the amount of columns of the board.
+	Width int `json:"width"`
+	// Height is the amount of rows of the board.
+	Height int `json:"height"`
+	// SpawnPolicy decides which values newly spawned tiles have.
+	SpawnPolicy SpawnPolicy `json:"spawnPolicy"`
+	// StartTiles is the amount of randomly placed tiles on a new board.
+	StartTiles int `json:"startTiles"`
+	// StartSpawnPolicy decides the values of the start tiles. If it is
+	// nil, SpawnPolicy is used.
+	StartSpawnPolicy SpawnPolicy `json:"startSpawnPolicy,omitempty"`
+	// InitialTiles is a custom start. If set, exactly these tiles are
+	// placed on a new board and StartTiles is ignored.
+	InitialTiles []Tile `json:"initialTiles,omitempty"`
+	// TargetTile is the tile value that wins the game once it has been
+	// created by a merge. Zero means there's no target at all.
+	TargetTile uint `json:"targetTile"`
+	// UndoLimit is the maximum amount of moves that can be undone in a
+	// row. Zero disables undoing, a negative value removes the limit.
+	UndoLimit int `json:"undoLimit"`
+}
+
+// Position addresses a cell on the board.
+type Position struct {
+	Row    int `json:"row"`
+	Column int `json:"column"`
+}
+
+// Tile is a value at a certain position on the board.
+type Tile struct {
+	Position
+	Value uint `json:"value"`
+}
+
+// DefaultRules returns the rules of the original 2048 game.
+func DefaultRules() Rules {
+	return Rules{
+		Width:       4,
+		Height:      4,
+		SpawnPolicy: DefaultSpawnPolicy(),
+		StartTiles:  2,
+		TargetTile:  2048,
+	}
+}
+
+// Validate checks whether a session can be played with these rules.
+func (rules Rules) Validate() error {
+	if rules.Width < MinSize || rules.Height < MinSize || rules.Width > MaxSize || rules.Height > MaxSize {
+		return fmt.Errorf("each dimension of the board has to be between %d and %d, but was %dx%d",
+			MinSize, MaxSize, rules.Width, rules.Height)
+	}
+	if err := rules.SpawnPolicy.Validate(); err != nil {
+		return fmt.Errorf("invalid spawn policy: %w", err)
+	}
+	if rules.TargetTile != 0 && !isValidTileValue(rules.TargetTile) {
+		return fmt.Errorf("target tile %d isn't a power of two", rules.TargetTile)
+	}
+
+	if len(rules.InitialTiles) > 0 {
+		occupied := make(map[Position]bool, len(rules.InitialTiles))
+		for _, tile := range rules.InitialTiles {
+			if tile.Row < 0 || tile.Row >= rules.Height || tile.Column < 0 || tile.Column >= rules.Width {
+				return fmt.Errorf("initial tile at %d:%d is outside of the board", tile.Row, tile.Column)
+			}
+			if !isValidTileValue(tile.Value) {
+				return fmt.Errorf("initial tile at %d:%d: %d isn't a power of two", tile.Row, tile.Column, tile.Value)
+			}
+			if occupied[tile.Position] {
+				return fmt.Errorf("more than one initial tile at %d:%d", tile.Row, tile.Column)
+			}
+			occupied[tile.Position] = true
+		}
+		return nil
+	}
+
+	if rules.StartTiles < 1 || rules.StartTiles > rules.Width*rules.Height {
+		return fmt.Errorf("amount of start tiles has to be between 1 and %d, but was %d",
+			rules.Width*rules.Height, rules.StartTiles)
+	}
+	if rules.StartSpawnPolicy != nil {
+		if err := rules.StartSpawnPolicy.Validate(); err != nil {
+			return fmt.Errorf("invalid start spawn policy: %w", err)
+		}
+	}
+	return nil
+}
+
+// startSpawnPolicy returns the policy used for placing the start tiles.
+func (rules Rules) startSpawnPolicy() SpawnPolicy {
+	if rules.StartSpawnPolicy != nil {
+		return rules.StartSpawnPolicy
+	}
+	return rules.SpawnPolicy
+}
+
+func isValidTileValue(value uint) bool {
+	return value >= 2 && value&(value-1) == 0
+}
+
+// SpawnWeight is a single entry of a SpawnPolicy.
+type SpawnWeight struct {
+	Value  uint    `json:"value"`
+	Weight float64 `json:"weight"`
+}
+
+// SpawnPolicy is a weighted table of the tile values that can spawn after
+// a move. The weights don't have to add up to one, as they are relative
+// to each other.
+type SpawnPolicy []SpawnWeight
+
+// DefaultSpawnPolicy returns the policy of the original game, which
+// spawns a 2 nine out of ten times and a 4 otherwise.
+func DefaultSpawnPolicy() SpawnPolicy {
+	return SpawnPolicy{
+		{Value: 2, Weight: 0.9},
+		{Value: 4, Weight: 0.1},
+	}
+}
+
+// Validate checks whether at least one value can be spawned and that all
+// values are valid tiles.
+func (policy SpawnPolicy) Validate() error {
+	var total float64
+	for _, entry := range policy {
+		if !isValidTileValue(entry.Value) {
+			return fmt.Errorf("%d isn't a power of two", entry.Value)
+		}
+		if math.IsNaN(entry.Weight) || math.IsInf(entry.Weight, 0) {
+			return fmt.Errorf("weight of %d isn't a finite number", entry.Value)
+		}
+		if entry.Weight < 0 {
+			return fmt.Errorf("weight of %d is negative", entry.Value)
+		}
+		total += entry.Weight
+	}
+
+	//Huge weights can add up to infinity, which breaks picking as well.
+	if math.IsInf(total, 0) {
+		return errors.New("sum of all weights is too large")
+	}
+	if total <= 0 {
+		return errors.New("no tile can ever be spawned")
+	}
+	return nil
+}
+
+// pick chooses a value based on the given random number, which has to be
+// in [0, 1).
+func (policy SpawnPolicy) pick(random float64) uint {
+	var total float64
+	for _, entry := range policy {
+		total += entry.Weight
+	}
+
+	threshold := random * total
+	for _, entry := range policy {
+		if threshold < entry.Weight {
+			return entry.Value
+		}
+		threshold -= entry.Weight
+	}
+
+	//Rounding errors could make us end up here.
+	for index := len(policy) - 1; index >= 0; index-- {
+		if policy[index].Weight > 0 {
+			return policy[index].Value
+		}
+	}
+	return 0
+}
+
+// Variant describes the rules that affect how comparable scores are,
+// except for the board size. The original rules are called "classic".
+func (rules Rules) Variant() string {
+	var parts []string
+	if !reflect.DeepEqual(rules.SpawnPolicy, DefaultSpawnPolicy()) {
+		parts = append(parts, "spawn "+rules.SpawnPolicy.String())
+	}
+	if len(rules.InitialTiles) > 0 {
+		parts = append(parts, "custom start")
+	} else {
+		if rules.StartTiles != 2 {
+			parts = append(parts, fmt.Sprintf("%d start tiles", rules.StartTiles))
+		}
+		if rules.StartSpawnPolicy != nil {
+			parts = append(parts, "start spawn "+rules.StartSpawnPolicy.String())
+		}
+	}
+	if rules.TargetTile == 0 {
+		parts = append(parts, "endless")
+	} else if rules.TargetTile != 2048 {
+		parts = append(parts, fmt.Sprintf("target %d", rules.TargetTile))
+	}
+
+	if len(parts) == 0 {
+		return "classic"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// String formats the policy as VALUE:WEIGHT pairs, for example
+// "2:0.9 4:0.1".
+func (policy SpawnPolicy) String() string {
+	entries := make([]string, 0, len(policy))
+	for _, entry := range policy {
+		entries = append(entries, fmt.Sprintf("%d:%g", entry.Value, entry.Weight))
+	}
+	return strings.Join(entries, " ")
+}
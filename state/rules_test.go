@@ -0,0 +1,221 @@
+package state
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestRules_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   func() Rules
+		wantErr bool
+	}{
+		{
+			name:  "default",
+			rules: DefaultRules,
+		},
+		{
+			name: "board too small",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.Width = 1
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "board too large",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.Height = 17
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty spawn policy",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = nil
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "spawn value not a power of two",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 3, Weight: 1}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative weight",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: 1}, {Value: 4, Weight: -1}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "NaN weight",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: math.NaN()}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "infinite weight",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: math.Inf(1)}, {Value: 4, Weight: 1}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative infinite weight",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: 1}, {Value: 4, Weight: math.Inf(-1)}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "weights adding up to infinity",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: math.MaxFloat64}, {Value: 4, Weight: math.MaxFloat64}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "more start tiles than cells",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.StartTiles = 17
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "no start tiles",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.StartTiles = 0
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "initial tile outside of board",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.InitialTiles = []Tile{{Position: Position{Row: 4, Column: 0}, Value: 2}}
+				return rules
+			},
+			wantErr: true,
+		},
+		{
+			name: "initial tiles overlap",
+			rules: func() Rules {
+				rules := DefaultRules()
+				rules.InitialTiles = []Tile{
+					{Position: Position{Row: 1, Column: 1}, Value: 2},
+					{Position: Position{Row: 1, Column: 1}, Value: 4},
+				}
+				return rules
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.rules().Validate()
+			if (err != nil) != test.wantErr {
+				t.Errorf("Expected error: %v, but got: %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestSpawnPolicy_pick(t *testing.T) {
+	policy := SpawnPolicy{
+		{Value: 2, Weight: 3},
+		{Value: 4, Weight: 0},
+		{Value: 8, Weight: 1},
+	}
+
+	tests := []struct {
+		random   float64
+		expected uint
+	}{
+		{random: 0, expected: 2},
+		{random: 0.74, expected: 2},
+		{random: 0.75, expected: 8},
+		{random: 0.9999, expected: 8},
+	}
+	for _, test := range tests {
+		if value := policy.pick(test.random); value != test.expected {
+			t.Errorf("Expected %d for %f, got %d", test.expected, test.random, value)
+		}
+	}
+}
+
+func TestSpawnPolicy_pickDefaultDistribution(t *testing.T) {
+	policy := DefaultSpawnPolicy()
+	random := rand.New(rand.NewSource(1))
+
+	var fours int
+	const samples = 100000
+	for i := 0; i < samples; i++ {
+		if policy.pick(random.Float64()) == 4 {
+			fours++
+		}
+	}
+
+	if ratio := float64(fours) / samples; ratio < 0.09 || ratio > 0.11 {
+		t.Errorf("Expected about 10%% fours, got %.2f%%", ratio*100)
+	}
+}
+
+func TestGameSession_PinnedSpawnPolicy(t *testing.T) {
+	rules := DefaultRules()
+	rules.SpawnPolicy = SpawnPolicy{{Value: 4, Weight: 1}}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	for _, row := range session.GameBoard {
+		for _, cell := range row {
+			if cell != 0 && cell != 4 {
+				t.Errorf("Expected only 4s to spawn, got %d", cell)
+			}
+		}
+	}
+}
+
+func TestRules_Variant(t *testing.T) {
+	rules := DefaultRules()
+	rules.Width, rules.Height = 5, 6
+	rules.UndoLimit = 3
+	if variant := rules.Variant(); variant != "classic" {
+		t.Errorf("Expected classic variant, got %q", variant)
+	}
+
+	rules.SpawnPolicy = SpawnPolicy{{Value: 2, Weight: 1}}
+	rules.TargetTile = 0
+	if variant := rules.Variant(); variant != "spawn 2:1, endless" {
+		t.Errorf("Expected custom variant, got %q", variant)
+	}
+}
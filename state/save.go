@@ -0,0 +1,114 @@
+package state
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+)
+
+// SaveVersion is the version of the SaveGame format written by this
+// package. It has to be increased whenever the format changes in an
+// incompatible way.
+const SaveVersion = 2
+
+// SaveGame is the serializable form of a GameSession, including its
+// history, so that a game can be resumed exactly where it was left.
+type SaveGame struct {
+	Version   int            `json:"version"`
+	Rules     Rules          `json:"rules"`
+	Seed      int64          `json:"seed"`
+	State     SessionState   `json:"state"`
+	UndoCount int            `json:"undoCount"`
+	HintCount int            `json:"hintCount,omitempty"`
+	UndoStack []SessionState `json:"undoStack,omitempty"`
+	RedoStack []SessionState `json:"redoStack,omitempty"`
+	// StartTiles are the tiles that were on the board before the first
+	// move.
+	StartTiles []Tile `json:"startTiles"`
+	// Log contains all moves, including undone moves that can be redone.
+	Log []LoggedMove `json:"log,omitempty"`
+}
+
+// Save produces a SaveGame holding the complete state of the session.
+func (session *GameSession) Save() SaveGame {
+	return SaveGame{
+		Version:    SaveVersion,
+		Rules:      session.rules,
+		Seed:       session.seed,
+		State:      session.snapshot(),
+		UndoCount:  session.undoCount,
+		HintCount:  session.hintCount,
+		UndoStack:  append([]SessionState(nil), session.undoStack...),
+		RedoStack:  append([]SessionState(nil), session.redoStack...),
+		StartTiles: append([]Tile(nil), session.startTiles...),
+		Log:        append([]LoggedMove(nil), session.log...),
+	}
+}
+
+// LoadGameSession restores a session from a SaveGame. An error is returned
+// if the save has an unsupported version or is inconsistent.
+func LoadGameSession(renderNotificationChannel chan bool, save SaveGame) (*GameSession, error) {
+	if save.Version != SaveVersion {
+		return nil, fmt.Errorf("unsupported save version %d, expected %d", save.Version, SaveVersion)
+	}
+	if err := save.Rules.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid rules: %w", err)
+	}
+
+	if err := validateState(save, save.State); err != nil {
+		return nil, fmt.Errorf("invalid state: %w", err)
+	}
+	for index, state := range save.UndoStack {
+		if err := validateState(save, state); err != nil {
+			return nil, fmt.Errorf("invalid undo entry %d: %w", index, err)
+		}
+	}
+	for index, state := range save.RedoStack {
+		if err := validateState(save, state); err != nil {
+			return nil, fmt.Errorf("invalid redo entry %d: %w", index, err)
+		}
+	}
+
+	source := newSource(save.Seed)
+	session := &GameSession{
+		Mutex:                     &sync.Mutex{},
+		renderNotificationChannel: renderNotificationChannel,
+		rules:                     save.Rules,
+		seed:                      save.Seed,
+		random:                    rand.New(source),
+		source:                    source,
+		undoStack:                 save.UndoStack,
+		redoStack:                 save.RedoStack,
+		undoCount:                 save.UndoCount,
+		hintCount:                 save.HintCount,
+		startTiles:                save.StartTiles,
+		log:                       save.Log,
+	}
+	session.restore(save.State)
+
+	return session, nil
+}
+
+func validateState(save SaveGame, state SessionState) error {
+	rules := save.Rules
+	if state.Board.Width() != rules.Width || state.Board.Height() != rules.Height {
+		return fmt.Errorf("board is %dx%d, but rules require %dx%d",
+			state.Board.Width(), state.Board.Height(), rules.Width, rules.Height)
+	}
+
+	for rowIndex, row := range state.Board {
+		if len(row) != rules.Width {
+			return fmt.Errorf("row %d has %d cells, expected %d", rowIndex, len(row), rules.Width)
+		}
+		for cellIndex, cell := range row {
+			if cell != 0 && !isValidTileValue(cell) {
+				return fmt.Errorf("tile at %d:%d has invalid value %d", rowIndex, cellIndex, cell)
+			}
+		}
+	}
+
+	if state.MoveCount < 0 || state.MoveCount > len(save.Log) {
+		return fmt.Errorf("move count %d doesn't match log of %d moves", state.MoveCount, len(save.Log))
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package state
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestGameSession_SaveAndLoad(t *testing.T) {
+	rules := DefaultRules()
+	rules.UndoLimit = -1
+	original, err := NewGameSession(nil, rules, 99)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	for index := 0; index < 20; index++ {
+		original.Move(Directions[index%len(Directions)])
+	}
+	original.Undo()
+	original.RecordHint()
+
+	data, err := json.Marshal(original.Save())
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	var save SaveGame
+	if err := json.Unmarshal(data, &save); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	loaded, err := LoadGameSession(nil, save)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	if loaded.Score() != original.Score() || loaded.MoveCount() != original.MoveCount() ||
+		loaded.UndoCount() != original.UndoCount() || loaded.HintCount() != original.HintCount() ||
+		loaded.Seed() != original.Seed() {
+		t.Fatalf("Loaded session differs from original")
+	}
+
+	//Both sessions have to continue identically, including redo.
+	loaded.Redo()
+	original.Redo()
+	for index := 0; index < 20; index++ {
+		original.Move(Directions[index%len(Directions)])
+		loaded.Move(Directions[index%len(Directions)])
+		if !reflect.DeepEqual(original.GameBoard, loaded.GameBoard) {
+			t.Fatalf("Sessions diverged after move %d:\n%s\n\n%s",
+				index, formatBoard(original.GameBoard), formatBoard(loaded.GameBoard))
+		}
+	}
+	for original.Undo() {
+		if !loaded.Undo() || !reflect.DeepEqual(original.GameBoard, loaded.GameBoard) {
+			t.Fatalf("Histories differ")
+		}
+	}
+}
+
+func TestLoadGameSession_Invalid(t *testing.T) {
+	session, _ := NewGameSession(nil, DefaultRules(), 1)
+
+	tests := []struct {
+		name   string
+		modify func(*SaveGame)
+	}{
+		{
+			name:   "unsupported version",
+			modify: func(save *SaveGame) { save.Version = SaveVersion + 1 },
+		},
+		{
+			name:   "invalid rules",
+			modify: func(save *SaveGame) { save.Rules.SpawnPolicy = nil },
+		},
+		{
+			name:   "board doesn't match rules",
+			modify: func(save *SaveGame) { save.State.Board = NewBoard(3, 3) },
+		},
+		{
+			name:   "invalid tile",
+			modify: func(save *SaveGame) { save.State.Board[0][0] = 3 },
+		},
+		{
+			name: "invalid history",
+			modify: func(save *SaveGame) {
+				save.UndoStack = []SessionState{{Board: NewBoard(4, 3)}}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			save := session.Save()
+			save.State.Board = save.State.Board.Copy()
+			test.modify(&save)
+			if _, err := LoadGameSession(nil, save); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -5,357 +5,261 @@ import (
 	"sync"
 )
 
+// GameSession is a stateful game, played by a certain set of rules. It
+// wraps a Board, which implements the actual game logic.
 type GameSession struct {
 	Mutex                     *sync.Mutex
 	renderNotificationChannel chan bool
-
-	score     uint
-	GameOver  bool
-	GameBoard [4][4]uint
+	rules                     Rules
+	seed                      int64
+	// random is the only source of randomness for the session. It must
+	// only be read via Intn and Float64, as Read buffers state outside of
+	// the underlying source.
+	random *rand.Rand
+	source *source
+
+	// score is the sum of all tiles created by merges, as in the
+	// original game.
+	score    uint
+	GameOver bool
+	// Won is set once the target tile has been reached and stays set,
+	// even if the player keeps on playing.
+	Won bool
+	// keepPlaying is set once the player decided to continue after
+	// winning.
+	keepPlaying bool
+
+	moveCount int
+	// startTiles are the tiles on the board before the first move.
+	startTiles []Tile
+	// log contains all moves that changed the board. Only the first
+	// moveCount entries are part of the game, the rest has been undone
+	// and can be redone.
+	log []LoggedMove
+
+	undoStack []SessionState
+	redoStack []SessionState
+	undoCount int
+	hintCount int
+
+	GameBoard Board
 }
 
-// NewGameSession produces a ready-to-use session state.
-func NewGameSession(renderNotificationChannel chan bool) *GameSession {
+// NewGameSession produces a ready-to-use session state that is played by
+// the given rules. Two sessions with the same rules and seed will spawn
+// the same tiles given the same moves. An error is returned if the rules
+// are invalid.
+func NewGameSession(renderNotificationChannel chan bool, rules Rules, seed int64) (*GameSession, error) {
+	if err := rules.Validate(); err != nil {
+		return nil, err
+	}
+
+	source := newSource(seed)
 	session := &GameSession{
 		Mutex:                     &sync.Mutex{},
 		renderNotificationChannel: renderNotificationChannel,
+		rules:                     rules,
+		seed:                      seed,
+		random:                    rand.New(source),
+		source:                    source,
 
 		score:     0,
 		GameOver:  false,
-		GameBoard: [4][4]uint{},
+		GameBoard: NewBoard(rules.Width, rules.Height),
 	}
 
-	//We want to start off with one filled cell.
-	session.fillCell()
-
-	return session
-}
-
-func isGameOver(board [4][4]uint) bool {
-	for _, row := range board {
-		var prevCell uint
-		for _, cell := range row {
-			//At least one more left / right move possible.
-			if cell == 0 || prevCell == cell {
-				return false
-			}
-
-			prevCell = cell
+	if len(rules.InitialTiles) > 0 {
+		for _, tile := range rules.InitialTiles {
+			session.GameBoard[tile.Row][tile.Column] = tile.Value
+		}
+	} else {
+		for i := 0; i < rules.StartTiles; i++ {
+			session.fillCell(rules.startSpawnPolicy())
 		}
 	}
-
-	for cellIndex := 0; cellIndex < len(board); cellIndex++ {
-		var prevCell uint
-		for rowIndex := 0; rowIndex < len(board); rowIndex++ {
-			cell := board[rowIndex][cellIndex]
-			//At least one more left / right move possible.
-			if cell == 0 || prevCell == cell {
-				return false
+	for rowIndex, row := range session.GameBoard {
+		for cellIndex, cell := range row {
+			if cell != 0 {
+				session.startTiles = append(session.startTiles, Tile{
+					Position: Position{Row: rowIndex, Column: cellIndex},
+					Value:    cell,
+				})
 			}
-
-			prevCell = cell
 		}
 	}
+	//A custom start might not allow for any move.
+	session.GameOver = session.GameBoard.IsTerminal()
 
-	return true
+	return session, nil
 }
 
-func (session *GameSession) update() {
-	var score uint
-	for _, row := range session.GameBoard {
-		for _, cell := range row {
-			score += cell
-		}
-	}
-	session.score = score
-	session.GameOver = isGameOver(session.GameBoard)
-
-	// In order to avoid dead-locking the caller.
-	go func() {
-		session.renderNotificationChannel <- true
-	}()
+// AwaitingDecision indicates that the game has just been won and the
+// player has to decide whether to keep playing. No moves are possible
+// until KeepPlaying has been called.
+func (session *GameSession) AwaitingDecision() bool {
+	return session.Won && !session.keepPlaying
 }
 
-func (session *GameSession) fillCell() {
-	if session.GameOver {
+// KeepPlaying continues a won game in endless mode. No further wins will
+// be reported for this session.
+func (session *GameSession) KeepPlaying() {
+	if !session.AwaitingDecision() {
 		return
 	}
 
-	var freeIndices [][2]int
-	for rowIndex, row := range session.GameBoard {
-		for cellIndex, cell := range row {
-			if cell == 0 {
-				freeIndices = append(freeIndices, [2]int{rowIndex, cellIndex})
-			}
-		}
-	}
-
-	if len(freeIndices) == 0 {
-		session.GameOver = true
-		return
-	}
-
-	indexToFill := freeIndices[rand.Intn(len(freeIndices))]
-	session.GameBoard[indexToFill[0]][indexToFill[1]] = 2
+	session.keepPlaying = true
+	session.update()
 }
 
-func (session *GameSession) Down() {
-	if session.downNoFill() {
-		session.fillCell()
-		session.update()
-	}
+// Rules returns the rules this session is played by.
+func (session *GameSession) Rules() Rules {
+	return session.rules
 }
 
-// downNoFill is necessary for proper unit testing without the
-// randomness factor.
-func (session *GameSession) downNoFill() bool {
-	if session.GameOver {
-		return false
-	}
+// Seed returns the seed the session has been created with.
+func (session *GameSession) Seed() int64 {
+	return session.seed
+}
 
-	var hasChanged bool
-	for cellIndex := 0; cellIndex < len(session.GameBoard); cellIndex++ {
-		//Combination run
-		//We combine from top to bottom, since that's how the original game
-		//does it. So 2,2,2,0 would become 4,0,2,0
-		if session.combineVertically(
-			len(session.GameBoard)-1,
-			func(i int) bool { return i >= 0 },
-			func(i int) int { return i - 1 },
-			cellIndex) {
-			hasChanged = true
-		}
+// Width returns the amount of columns of the board.
+func (session *GameSession) Width() int {
+	return session.GameBoard.Width()
+}
 
-		//Shifting run
-		//The previously combined 4,0,2,0 now becomes 4,2,0,0
-		for rowIndex := len(session.GameBoard) - 2; rowIndex >= 0; rowIndex-- {
-			cell := session.GameBoard[rowIndex][cellIndex]
-			if cell == 0 {
-				continue
-			}
+// Height returns the amount of rows of the board.
+func (session *GameSession) Height() int {
+	return session.GameBoard.Height()
+}
 
-			moveTo := -1
-			for tempRowIndex := rowIndex + 1; tempRowIndex < len(session.GameBoard); tempRowIndex++ {
-				if session.GameBoard[tempRowIndex][cellIndex] == 0 {
-					moveTo = tempRowIndex
-				} else {
-					break
-				}
-			}
+func (session *GameSession) update() {
+	session.GameOver = session.GameBoard.IsTerminal()
+	session.notify()
+}
 
-			if moveTo != -1 {
-				session.GameBoard[moveTo][cellIndex] = cell
-				session.GameBoard[rowIndex][cellIndex] = 0
-				hasChanged = true
-			}
-		}
+func (session *GameSession) notify() {
+	//Headless sessions don't need to notify anyone.
+	if session.renderNotificationChannel == nil {
+		return
 	}
 
-	return hasChanged
+	// In order to avoid dead-locking the caller.
+	go func() {
+		session.renderNotificationChannel <- true
+	}()
 }
 
-func (session *GameSession) Up() {
-	if session.upNoFill() {
-		session.fillCell()
-		session.update()
+// checkTarget marks the game as won if the newly merged value reaches the
+// target tile.
+func (session *GameSession) checkTarget(mergedValue uint) {
+	if session.rules.TargetTile != 0 && mergedValue >= session.rules.TargetTile {
+		session.Won = true
 	}
 }
 
-func (session *GameSession) upNoFill() bool {
+// fillCell places a new tile on a random free cell and returns it. If
+// there's no free cell, nil is returned.
+func (session *GameSession) fillCell(policy SpawnPolicy) *Tile {
 	if session.GameOver {
-		return false
-	}
-
-	var hasChanged bool
-	for cellIndex := 0; cellIndex < len(session.GameBoard); cellIndex++ {
-		//Combination run
-		//We combine from top to bottom, since that's how the original game
-		//does it. So 2,2,2,0 would become 4,0,2,0
-		if session.combineVertically(
-			0,
-			func(i int) bool { return i < len(session.GameBoard) },
-			func(i int) int { return i + 1 },
-			cellIndex) {
-			hasChanged = true
-		}
-
-		//Shifting run
-		//The previously combined 4,0,2,0 now becomes 4,2,0,0
-		for rowIndex := 1; rowIndex < len(session.GameBoard); rowIndex++ {
-			cell := session.GameBoard[rowIndex][cellIndex]
-			if cell == 0 {
-				continue
-			}
-
-			moveTo := -1
-			for tempRowIndex := rowIndex - 1; tempRowIndex >= 0; tempRowIndex-- {
-				if session.GameBoard[tempRowIndex][cellIndex] == 0 {
-					moveTo = tempRowIndex
-				} else {
-					break
-				}
-			}
-
-			if moveTo != -1 {
-				session.GameBoard[moveTo][cellIndex] = cell
-				session.GameBoard[rowIndex][cellIndex] = 0
-				hasChanged = true
-			}
-		}
+		return nil
 	}
 
-	return hasChanged
-}
-
-func (session *GameSession) combineVertically(start int, resume func(int) bool, update func(int) int, cellIndex int) bool {
-	var hasChanged bool
-	indexLastNonZero := -1
-	for rowIndex := start; resume(rowIndex); rowIndex = update(rowIndex) {
-		cell := session.GameBoard[rowIndex][cellIndex]
-		if cell == 0 {
-			continue
-		}
-
-		if indexLastNonZero == -1 || cell != session.GameBoard[indexLastNonZero][cellIndex] {
-			indexLastNonZero = rowIndex
-			continue
-		}
-
-		session.GameBoard[indexLastNonZero][cellIndex] = cell * 2
-		session.GameBoard[rowIndex][cellIndex] = 0
-		indexLastNonZero = -1
-		hasChanged = true
+	freeCells := session.GameBoard.EmptyCells()
+	if len(freeCells) == 0 {
+		session.GameOver = true
+		return nil
 	}
 
-	return hasChanged
-}
-
-func (session *GameSession) Left() {
-	if session.leftNoFill() {
-		session.fillCell()
-		session.update()
+	tile := &Tile{
+		Position: freeCells[session.random.Intn(len(freeCells))],
+		Value:    policy.pick(session.random.Float64()),
 	}
+	session.GameBoard = session.GameBoard.Spawn(tile.Position, tile.Value)
+	return tile
 }
 
-func (session *GameSession) leftNoFill() bool {
-	if session.GameOver {
-		return false
+// Move moves all tiles in the given direction and spawns a new tile if
+// anything changed. Moves aren't possible while the game is over or
+// awaiting a decision after a win.
+func (session *GameSession) Move(direction Direction) MoveResult {
+	if session.GameOver || session.AwaitingDecision() {
+		return MoveResult{Direction: direction, GameOver: session.GameOver}
 	}
 
-	var hasChanged bool
-	for rowIndex := 0; rowIndex < len(session.GameBoard); rowIndex++ {
-		//Combination run
-		if session.combineHorizontally(0,
-			func(i int) bool { return i < len(session.GameBoard) },
-			func(i int) int { return i + 1 },
-			rowIndex) {
-			hasChanged = true
-		}
-
-		//Shifting run
-		//The previously combined 4,0,2,0 now becomes 4,2,0,0
-		for cellIndex := 1; cellIndex < len(session.GameBoard); cellIndex++ {
-			cell := session.GameBoard[rowIndex][cellIndex]
-			if cell == 0 {
-				continue
-			}
-
-			moveTo := -1
-			for tempCellIndex := cellIndex - 1; tempCellIndex >= 0; tempCellIndex-- {
-				if session.GameBoard[rowIndex][tempCellIndex] == 0 {
-					moveTo = tempCellIndex
-				} else {
-					break
-				}
-			}
-
-			if moveTo != -1 {
-				session.GameBoard[rowIndex][moveTo] = cell
-				session.GameBoard[rowIndex][cellIndex] = 0
-				hasChanged = true
-			}
+	board, result := session.GameBoard.Move(direction)
+	if result.Changed {
+		session.record(session.snapshot())
+		session.GameBoard = board
+		session.score += result.ScoreGained
+		for _, merge := range result.Merges {
+			session.checkTarget(merge.Value)
 		}
+		result.Spawn = session.fillCell(session.rules.SpawnPolicy)
+		session.log = append(session.log[:session.moveCount], LoggedMove{
+			Direction: direction,
+			Spawn:     result.Spawn,
+		})
+		session.moveCount++
+		session.update()
 	}
 
-	return hasChanged
+	result.GameOver = session.GameOver
+	return result
 }
 
-func (session *GameSession) Right() {
-	if session.rightNoFill() {
-		session.fillCell()
-		session.update()
-	}
+// Up moves all tiles up. See Move.
+func (session *GameSession) Up() MoveResult {
+	return session.Move(Up)
 }
 
-func (session *GameSession) rightNoFill() bool {
-	if session.GameOver {
-		return false
-	}
-
-	var hasChanged bool
-	for rowIndex := 0; rowIndex < len(session.GameBoard); rowIndex++ {
-		//Combination run
-		//We combine from top to bottom, since that's how the original game
-		//does it. So 2,2,2,0 would become 4,0,2,0
-		if session.combineHorizontally(
-			len(session.GameBoard)-1,
-			func(i int) bool { return i >= 0 },
-			func(i int) int { return i - 1 },
-			rowIndex) {
-			hasChanged = true
-		}
+// Down moves all tiles down. See Move.
+func (session *GameSession) Down() MoveResult {
+	return session.Move(Down)
+}
 
-		//Shifting run
-		//The previously combined 4,0,2,0 now becomes 4,2,0,0
-		for cellIndex := len(session.GameBoard) - 2; cellIndex >= 0; cellIndex-- {
-			cell := session.GameBoard[rowIndex][cellIndex]
-			if cell == 0 {
-				continue
-			}
+// Left moves all tiles to the left. See Move.
+func (session *GameSession) Left() MoveResult {
+	return session.Move(Left)
+}
 
-			moveTo := -1
-			for tempCellIndex := cellIndex + 1; tempCellIndex < len(session.GameBoard); tempCellIndex++ {
-				if session.GameBoard[rowIndex][tempCellIndex] == 0 {
-					moveTo = tempCellIndex
-				} else {
-					break
-				}
-			}
+// Right moves all tiles to the right. See Move.
+func (session *GameSession) Right() MoveResult {
+	return session.Move(Right)
+}
 
-			if moveTo != -1 {
-				session.GameBoard[rowIndex][moveTo] = cell
-				session.GameBoard[rowIndex][cellIndex] = 0
-				hasChanged = true
-			}
-		}
-	}
+// Score returns the standard 2048 score, which is the sum of the values
+// of all tiles that have been created by merging two tiles.
+func (session *GameSession) Score() uint {
+	return session.score
+}
 
-	return hasChanged
+// MoveCount returns the amount of moves that changed the board.
+func (session *GameSession) MoveCount() int {
+	return session.moveCount
 }
 
-func (session *GameSession) combineHorizontally(start int, resume func(int) bool, update func(int) int, rowIndex int) bool {
-	var hasChanged bool
-	indexLastNonZero := -1
-	for cellIndex := start; resume(cellIndex); cellIndex = update(cellIndex) {
-		cell := session.GameBoard[rowIndex][cellIndex]
-		if cell == 0 {
-			continue
-		}
+// RecordHint counts a hint shown to the player. Scores of sessions with
+// hints aren't comparable to scores of regular sessions.
+func (session *GameSession) RecordHint() {
+	session.hintCount++
+}
 
-		if indexLastNonZero == -1 || cell != session.GameBoard[rowIndex][indexLastNonZero] {
-			indexLastNonZero = cellIndex
-			continue
-		}
+// HintCount returns how many hints have been shown in this session.
+func (session *GameSession) HintCount() int {
+	return session.hintCount
+}
 
-		session.GameBoard[rowIndex][indexLastNonZero] = cell * 2
-		session.GameBoard[rowIndex][cellIndex] = 0
-		indexLastNonZero = -1
-		hasChanged = true
-	}
+// StartTiles returns the tiles that were on the board before the first
+// move.
+func (session *GameSession) StartTiles() []Tile {
+	return append([]Tile(nil), session.startTiles...)
+}
 
-	return hasChanged
+// Log returns all moves of the game so far, excluding undone moves.
+func (session *GameSession) Log() []LoggedMove {
+	return append([]LoggedMove(nil), session.log[:session.moveCount]...)
 }
 
-func (session *GameSession) Score() uint {
-	return session.score
+// TileSum returns the sum of all tiles currently on the board.
+func (session *GameSession) TileSum() uint {
+	return session.GameBoard.Sum()
 }
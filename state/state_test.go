@@ -11,14 +11,14 @@ func TestGameSession_Down(t *testing.T) {
 	tests := []shiftTest{
 		{
 			name: "combine twice in one column and shift both",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{4, 0, 0, 0},
@@ -27,14 +27,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (1)",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -43,14 +43,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (2)",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -59,14 +59,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (3)",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -75,14 +75,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "shift one cell",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -91,14 +91,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "combine twice and shift both, but last column",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 4},
@@ -107,14 +107,14 @@ func TestGameSession_Down(t *testing.T) {
 		},
 		{
 			name: "do nothing",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
 				{0, 0, 0, 16},
 			},
-			move: func(session *GameSession) func() bool { return session.downNoFill },
-			expectedBoard: [4][4]uint{
+			move: Down,
+			expectedBoard: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
@@ -130,14 +130,14 @@ func TestGameSession_Up(t *testing.T) {
 	tests := []shiftTest{
 		{
 			name: "combine twice in one column and shift both",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{4, 0, 0, 0},
 				{4, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -146,14 +146,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (1)",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{4, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -162,14 +162,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (2)",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{4, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -178,14 +178,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "shift one cell",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -194,14 +194,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "shift one cell all the way",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -210,14 +210,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "combine twice and shift both, but last column",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{0, 0, 0, 4},
 				{0, 0, 0, 4},
 				{0, 0, 0, 0},
@@ -226,14 +226,14 @@ func TestGameSession_Up(t *testing.T) {
 		},
 		{
 			name: "do nothing",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
 				{0, 0, 0, 16},
 			},
-			move: func(session *GameSession) func() bool { return session.upNoFill },
-			expectedBoard: [4][4]uint{
+			move: Up,
+			expectedBoard: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
@@ -249,14 +249,14 @@ func TestGameSession_Left(t *testing.T) {
 	tests := []shiftTest{
 		{
 			name: "combine twice in one column and shift both",
-			board: [4][4]uint{
+			board: Board{
 				{2, 2, 2, 2},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{4, 4, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -265,14 +265,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (1)",
-			board: [4][4]uint{
+			board: Board{
 				{0, 2, 2, 2},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{4, 2, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -281,14 +281,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (2)",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 2, 2},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{4, 2, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
@@ -297,14 +297,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "shift one cell",
-			board: [4][4]uint{
+			board: Board{
 				{0, 2, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -313,14 +313,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "shift one cell all the way",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -329,14 +329,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "combine twice and shift both, but last column",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 2, 2, 2},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -345,14 +345,14 @@ func TestGameSession_Left(t *testing.T) {
 		},
 		{
 			name: "do nothing",
-			board: [4][4]uint{
+			board: Board{
 				{2, 4, 8, 16},
 				{4, 0, 0, 0},
 				{8, 0, 0, 0},
 				{16, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.leftNoFill },
-			expectedBoard: [4][4]uint{
+			move: Left,
+			expectedBoard: Board{
 				{2, 4, 8, 16},
 				{4, 0, 0, 0},
 				{8, 0, 0, 0},
@@ -368,14 +368,14 @@ func TestGameSession_Right(t *testing.T) {
 	tests := []shiftTest{
 		{
 			name: "combine twice in one column and shift both",
-			board: [4][4]uint{
+			board: Board{
 				{2, 2, 2, 2},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 4, 4},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
@@ -384,14 +384,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (1)",
-			board: [4][4]uint{
+			board: Board{
 				{0, 2, 2, 2},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 2, 4},
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
@@ -400,14 +400,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "combine once in one column and shift one cell (2)",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 2, 2},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 2, 4},
 				{0, 0, 0, 0},
 				{0, 0, 0, 2},
@@ -416,14 +416,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "shift one cell",
-			board: [4][4]uint{
+			board: Board{
 				{0, 2, 0, 0},
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 2},
 				{0, 0, 0, 0},
@@ -432,14 +432,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "shift one cell all the way",
-			board: [4][4]uint{
+			board: Board{
 				{2, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 0, 0, 0},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 0, 2},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -448,14 +448,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "combine twice and shift both, but last column",
-			board: [4][4]uint{
+			board: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{2, 2, 2, 2},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
 				{0, 0, 0, 0},
@@ -464,14 +464,14 @@ func TestGameSession_Right(t *testing.T) {
 		},
 		{
 			name: "do nothing",
-			board: [4][4]uint{
+			board: Board{
 				{2, 4, 8, 16},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
 				{0, 0, 0, 16},
 			},
-			move: func(session *GameSession) func() bool { return session.rightNoFill },
-			expectedBoard: [4][4]uint{
+			move: Right,
+			expectedBoard: Board{
 				{2, 4, 8, 16},
 				{0, 0, 0, 4},
 				{0, 0, 0, 8},
@@ -483,30 +483,358 @@ func TestGameSession_Right(t *testing.T) {
 	runShiftTests(t, tests)
 }
 
+func TestGameSession_NonSquareBoards(t *testing.T) {
+	tests := []shiftTest{
+		{
+			name: "3x3 left",
+			board: Board{
+				{2, 2, 2},
+				{0, 4, 4},
+				{8, 0, 8},
+			},
+			move: Left,
+			expectedBoard: Board{
+				{4, 2, 0},
+				{8, 0, 0},
+				{16, 0, 0},
+			},
+		},
+		{
+			name: "4 wide, 6 high down",
+			board: Board{
+				{2, 0, 0, 4},
+				{2, 0, 0, 0},
+				{0, 0, 0, 0},
+				{2, 0, 0, 4},
+				{0, 0, 0, 0},
+				{2, 0, 8, 0},
+			},
+			move: Down,
+			expectedBoard: Board{
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{4, 0, 0, 0},
+				{4, 0, 8, 8},
+			},
+		},
+		{
+			name: "4 wide, 6 high up",
+			board: Board{
+				{0, 0, 0, 0},
+				{0, 2, 0, 0},
+				{0, 0, 0, 0},
+				{0, 2, 0, 0},
+				{0, 0, 0, 0},
+				{0, 4, 0, 2},
+			},
+			move: Up,
+			expectedBoard: Board{
+				{0, 4, 0, 2},
+				{0, 4, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+				{0, 0, 0, 0},
+			},
+		},
+		{
+			name: "6 wide, 4 high right",
+			board: Board{
+				{2, 2, 0, 2, 0, 2},
+				{0, 0, 0, 0, 0, 0},
+				{4, 0, 0, 0, 0, 0},
+				{0, 0, 0, 0, 0, 0},
+			},
+			move: Right,
+			expectedBoard: Board{
+				{0, 0, 0, 0, 4, 4},
+				{0, 0, 0, 0, 0, 0},
+				{0, 0, 0, 0, 0, 4},
+				{0, 0, 0, 0, 0, 0},
+			},
+		},
+	}
+
+	runShiftTests(t, tests)
+}
+
+func TestNewGameSession_Dimensions(t *testing.T) {
+	for _, size := range [][2]int{{3, 3}, {4, 4}, {5, 5}, {6, 6}, {4, 6}, {6, 4}} {
+		rules := DefaultRules()
+		rules.Width, rules.Height = size[0], size[1]
+		session, err := NewGameSession(nil, rules, 1)
+		if err != nil {
+			t.Fatalf("Unexpected error for %dx%d board: %s", size[0], size[1], err)
+		}
+		if session.Width() != size[0] || session.Height() != size[1] {
+			t.Errorf("Expected %dx%d board, got %dx%d",
+				size[0], size[1], session.Width(), session.Height())
+		}
+
+		var filled int
+		for _, row := range session.GameBoard {
+			for _, cell := range row {
+				if cell != 0 {
+					filled++
+				}
+			}
+		}
+		if filled != 2 {
+			t.Errorf("Expected two filled cells on %dx%d board, got %d", size[0], size[1], filled)
+		}
+	}
+}
+
+func TestNewGameSession_StartTiles(t *testing.T) {
+	rules := DefaultRules()
+	rules.StartTiles = 5
+	rules.StartSpawnPolicy = SpawnPolicy{{Value: 8, Weight: 1}}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	var filled int
+	for _, row := range session.GameBoard {
+		for _, cell := range row {
+			if cell == 0 {
+				continue
+			}
+			filled++
+			if cell != 8 {
+				t.Errorf("Expected start tiles to be 8, got %d", cell)
+			}
+		}
+	}
+	if filled != 5 {
+		t.Errorf("Expected five start tiles, got %d", filled)
+	}
+}
+
+func TestNewGameSession_CustomStart(t *testing.T) {
+	rules := DefaultRules()
+	rules.Width, rules.Height = 3, 2
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 2}, Value: 1024},
+		{Position: Position{Row: 1, Column: 0}, Value: 2},
+	}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	expected := Board{
+		{0, 0, 1024},
+		{2, 0, 0},
+	}
+	if !reflect.DeepEqual(session.GameBoard, expected) {
+		t.Fatalf("Incorrect board:\nExpected:\n%s\nActual:  \n%s",
+			formatBoard(expected), formatBoard(session.GameBoard))
+	}
+	if session.GameOver {
+		t.Error("Expected game not to be over")
+	}
+
+	rules.Width, rules.Height = 2, 2
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 2},
+		{Position: Position{Row: 0, Column: 1}, Value: 4},
+		{Position: Position{Row: 1, Column: 0}, Value: 4},
+		{Position: Position{Row: 1, Column: 1}, Value: 2},
+	}
+	session, err = NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if !session.GameOver {
+		t.Error("Expected a custom start without possible moves to be over")
+	}
+}
+
+func TestGameSession_Win(t *testing.T) {
+	rules := DefaultRules()
+	rules.TargetTile = 16
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 8},
+		{Position: Position{Row: 0, Column: 1}, Value: 8},
+		{Position: Position{Row: 3, Column: 0}, Value: 8},
+		{Position: Position{Row: 3, Column: 1}, Value: 8},
+	}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	session.Right()
+	if !session.Won || !session.AwaitingDecision() {
+		t.Fatalf("Expected game to be won and awaiting a decision")
+	}
+
+	board := formatBoard(session.GameBoard)
+	session.Left()
+	if formatBoard(session.GameBoard) != board {
+		t.Fatalf("Expected no moves to be possible before deciding to keep playing")
+	}
+
+	session.KeepPlaying()
+	if !session.Won || session.AwaitingDecision() {
+		t.Fatalf("Expected game to stay won, but not await a decision anymore")
+	}
+
+	//Merging the two 16s doesn't require another decision.
+	session.Down()
+	if session.AwaitingDecision() {
+		t.Fatalf("Expected win to only be reported once")
+	}
+}
+
+func TestGameSession_NoTarget(t *testing.T) {
+	rules := DefaultRules()
+	rules.TargetTile = 0
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 2048},
+		{Position: Position{Row: 0, Column: 1}, Value: 2048},
+	}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	session.Left()
+	if session.Won {
+		t.Fatalf("Expected game without target to never be won")
+	}
+}
+
+func TestBoard_IsTerminal(t *testing.T) {
+	tests := []struct {
+		name     string
+		board    Board
+		gameOver bool
+	}{
+		{
+			name: "free cell",
+			board: Board{
+				{2, 4, 8},
+				{4, 8, 2},
+				{8, 2, 0},
+			},
+			gameOver: false,
+		},
+		{
+			name: "horizontal merge possible on wide board",
+			board: Board{
+				{2, 4, 2, 4, 2, 4},
+				{4, 2, 4, 2, 4, 4},
+			},
+			gameOver: false,
+		},
+		{
+			name: "vertical merge possible in last column of wide board",
+			board: Board{
+				{2, 4, 2, 4, 2, 8},
+				{4, 2, 4, 2, 4, 8},
+			},
+			gameOver: false,
+		},
+		{
+			name: "stuck on tall board",
+			board: Board{
+				{2, 4},
+				{4, 2},
+				{2, 4},
+				{4, 2},
+			},
+			gameOver: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if gameOver := test.board.IsTerminal(); gameOver != test.gameOver {
+				t.Errorf("Expected game over to be %v, but was %v", test.gameOver, gameOver)
+			}
+		})
+	}
+}
+
+func TestGameSession_Score(t *testing.T) {
+	rules := DefaultRules()
+	rules.InitialTiles = []Tile{
+		{Position: Position{Row: 0, Column: 0}, Value: 2},
+		{Position: Position{Row: 0, Column: 1}, Value: 2},
+		{Position: Position{Row: 0, Column: 2}, Value: 4},
+		{Position: Position{Row: 0, Column: 3}, Value: 4},
+		{Position: Position{Row: 1, Column: 0}, Value: 8},
+		{Position: Position{Row: 1, Column: 1}, Value: 8},
+		{Position: Position{Row: 2, Column: 0}, Value: 2},
+	}
+	session, err := NewGameSession(nil, rules, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+
+	result := session.Left()
+	if session.Score() != 4+8+16 || result.ScoreGained != 4+8+16 {
+		t.Errorf("Expected score %d after first move, got %d", 4+8+16, session.Score())
+	}
+
+	if expected := 2 + 4 + 8 + 16 + result.Spawn.Value; session.TileSum() != expected {
+		t.Errorf("Expected tile sum %d, got %d", expected, session.TileSum())
+	}
+}
+
 type shiftTest struct {
 	name          string
-	board         [4][4]uint
-	move          func(*GameSession) func() bool
-	expectedBoard [4][4]uint
+	board         Board
+	move          Direction
+	expectedBoard Board
 }
 
 func runShiftTests(t *testing.T, tests []shiftTest) {
 	for _, test := range tests {
-		session := &GameSession{
-			GameOver:  false,
-			GameBoard: test.board,
-		}
 		t.Run(test.name, func(t *testing.T) {
-			test.move(session)()
-			if !reflect.DeepEqual(session.GameBoard, test.expectedBoard) {
+			original := test.board.Copy()
+			board, result := test.board.Move(test.move)
+			if !reflect.DeepEqual(board, test.expectedBoard) {
 				t.Fatalf("Incorrect board:\nExpected:\n%s\nActual:  \n%s",
-					formatBoard(test.expectedBoard), formatBoard(session.GameBoard))
+					formatBoard(test.expectedBoard), formatBoard(board))
+			}
+			if !reflect.DeepEqual(test.board, original) {
+				t.Fatalf("Move modified the original board:\n%s", formatBoard(test.board))
+			}
+
+			changed := !reflect.DeepEqual(original, test.expectedBoard)
+			if result.Changed != changed || test.board.CanMove(test.move) != changed {
+				t.Fatalf("Expected change to be %v, but result said %v and CanMove said %v",
+					changed, result.Changed, test.board.CanMove(test.move))
+			}
+
+			//The bitboard engine only supports 4x4 boards, but has to
+			//behave exactly the same.
+			if test.board.Width() != 4 || test.board.Height() != 4 {
+				return
+			}
+			bitboard, err := NewBitboard(test.board)
+			if err != nil {
+				t.Fatalf("Unexpected error converting to bitboard: %s", err)
+			}
+			movedBitboard, bitboardResult := bitboard.Move(test.move)
+			if !reflect.DeepEqual(movedBitboard.Board(), test.expectedBoard) {
+				t.Fatalf("Incorrect bitboard:\nExpected:\n%s\nActual:  \n%s",
+					formatBoard(test.expectedBoard), formatBoard(movedBitboard.Board()))
+			}
+			if bitboardResult.Changed != changed || bitboard.CanMove(test.move) != changed ||
+				bitboardResult.ScoreGained != result.ScoreGained {
+				t.Fatalf("Bitboard result %+v doesn't match board result %+v", bitboardResult, result)
 			}
 		})
 	}
 }
 
-func formatBoard(board [4][4]uint) string {
+func formatBoard(board Board) string {
 	var buffer strings.Builder
 	for rowIndex, row := range board {
 		if rowIndex != 0 {
@@ -0,0 +1,142 @@
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// DefaultHighScoreLimit is the amount of entries kept in the high score
+// table.
+const DefaultHighScoreLimit = 10
+
+// HighScore is a single finished game in the high score table.
+type HighScore struct {
+	Score    uint          `json:"score"`
+	MaxTile  uint          `json:"maxTile"`
+	Moves    int           `json:"moves"`
+	Duration time.Duration `json:"duration"`
+	Date     time.Time     `json:"date"`
+	Width    int           `json:"width"`
+	Height   int           `json:"height"`
+	// Variant describes the rules the game was played with, see
+	// state.Rules.Variant.
+	Variant string `json:"variant"`
+	// Undos is the amount of undos used. Games with undos aren't
+	// comparable with games without.
+	Undos int `json:"undos"`
+	// Hints is the amount of hints shown during the game.
+	Hints int `json:"hints,omitempty"`
+	// Player names the computer player that made moves in this game. It
+	// is empty for games played by a human only.
+	Player string `json:"player,omitempty"`
+	// Seed and Started identify the game, so that a game that is played
+	// on after being recorded replaces its earlier entry. Entries without
+	// a start are never replaced.
+	Seed    int64     `json:"seed,omitempty"`
+	Started time.Time `json:"started,omitempty"`
+}
+
+// sameGame indicates whether both entries have been recorded for the
+// same game.
+func (highScore HighScore) sameGame(other HighScore) bool {
+	return !highScore.Started.IsZero() && highScore.Seed == other.Seed && highScore.Started.Equal(other.Started)
+}
+
+// BoardSize formats the size of the board as WIDTHxHEIGHT.
+func (highScore HighScore) BoardSize() string {
+	return fmt.Sprintf("%dx%d", highScore.Width, highScore.Height)
+}
+
+// HighScoresPath returns the path of the default high score table.
+func HighScoresPath() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "highscores.json"), nil
+}
+
+// LoadHighScores reads the high score table, sorted by score. A missing
+// file counts as an empty table.
+func LoadHighScores(path string) ([]HighScore, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading high scores: %w", err)
+	}
+
+	var highScores []HighScore
+	if err := json.Unmarshal(data, &highScores); err != nil {
+		return nil, fmt.Errorf("high score file %s is corrupted: %w", path, err)
+	}
+	sortHighScores(highScores)
+	return highScores, nil
+}
+
+// AddHighScore inserts the entry into the table, only keeping the best
+// entries up to the given limit. An earlier entry of the same game, see
+// HighScore.Started, is replaced. Concurrent calls, even from different
+// processes, don't lose entries. The updated table is returned.
+func AddHighScore(path string, entry HighScore, limit int) ([]HighScore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, err
+	}
+
+	var highScores []HighScore
+	lockError := withLock(path, func() error {
+		var err error
+		highScores, err = LoadHighScores(path)
+		if err != nil {
+			return err
+		}
+
+		kept := highScores[:0]
+		for _, highScore := range highScores {
+			if !entry.sameGame(highScore) {
+				kept = append(kept, highScore)
+			}
+		}
+		highScores = append(kept, entry)
+		sortHighScores(highScores)
+		if len(highScores) > limit {
+			highScores = highScores[:limit]
+		}
+
+		data, err := json.MarshalIndent(highScores, "", "\t")
+		if err != nil {
+			return err
+		}
+		return WriteFileAtomic(path, data)
+	})
+	return highScores, lockError
+}
+
+// BestScore returns the best score achieved on a board of the given size
+// and rule variant.
+func BestScore(highScores []HighScore, width, height int, variant string) uint {
+	var best uint
+	for _, highScore := range highScores {
+		if highScore.Width == width && highScore.Height == height &&
+			highScore.Variant == variant && highScore.Score > best {
+			best = highScore.Score
+		}
+	}
+	return best
+}
+
+func sortHighScores(highScores []HighScore) {
+	//Older entries win ties, as they were there first.
+	sort.SliceStable(highScores, func(a, b int) bool {
+		if highScores[a].Score != highScores[b].Score {
+			return highScores[a].Score > highScores[b].Score
+		}
+		return highScores[a].Date.Before(highScores[b].Date)
+	})
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestAddHighScore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "highscores.json")
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for index, score := range []uint{100, 500, 300, 200} {
+		entry := HighScore{
+			Score:   score,
+			Date:    start.Add(time.Duration(index) * time.Hour),
+			Width:   4,
+			Height:  4,
+			Variant: "classic",
+		}
+		if _, err := AddHighScore(path, entry, 3); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	highScores, err := LoadHighScores(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(highScores) != 3 {
+		t.Fatalf("Expected table to be limited to 3 entries, got %d", len(highScores))
+	}
+	for index, expected := range []uint{500, 300, 200} {
+		if highScores[index].Score != expected {
+			t.Errorf("Expected score %d at %d, got %d", expected, index, highScores[index].Score)
+		}
+	}
+
+	if best := BestScore(highScores, 4, 4, "classic"); best != 500 {
+		t.Errorf("Expected best score 500, got %d", best)
+	}
+	if best := BestScore(highScores, 5, 5, "classic"); best != 0 {
+		t.Errorf("Expected no best score for different size, got %d", best)
+	}
+}
+
+func TestAddHighScore_Concurrent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "highscores.json")
+
+	var waitGroup sync.WaitGroup
+	for index := 0; index < 20; index++ {
+		waitGroup.Add(1)
+		go func(score uint) {
+			defer waitGroup.Done()
+			if _, err := AddHighScore(path, HighScore{Score: score}, 100); err != nil {
+				t.Errorf("Unexpected error: %s", err)
+			}
+		}(uint(index))
+	}
+	waitGroup.Wait()
+
+	highScores, err := LoadHighScores(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if len(highScores) != 20 {
+		t.Errorf("Expected no entry to be lost, got %d entries", len(highScores))
+	}
+}
+
+func TestAddHighScore_SameGame(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "highscores.json")
+	started := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	entries := []HighScore{
+		{Score: 100, Seed: 1, Started: started},
+		{Score: 50, Seed: 2, Started: started},
+		{Score: 200, Seed: 1, Started: started},
+		//Entries without a start are never replaced.
+		{Score: 10},
+		{Score: 20},
+	}
+	var highScores []HighScore
+	for _, entry := range entries {
+		var err error
+		if highScores, err = AddHighScore(path, entry, 10); err != nil {
+			t.Fatalf("Unexpected error: %s", err)
+		}
+	}
+
+	if len(highScores) != 4 {
+		t.Fatalf("Expected earlier entry of the same game to be replaced, got %+v", highScores)
+	}
+	for index, expected := range []uint{200, 50, 20, 10} {
+		if highScores[index].Score != expected {
+			t.Errorf("Expected score %d at %d, got %d", expected, index, highScores[index].Score)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockRetryInterval = 20 * time.Millisecond
+	lockTimeout       = 5 * time.Second
+	// staleLockAge is the age after which a lock is assumed to belong to
+	// a crashed process.
+	staleLockAge = 30 * time.Second
+)
+
+// withLock runs the given function while holding an exclusive lock for
+// the given path, which protects read-modify-write cycles across
+// processes. The lock is a separate file, so it works on every platform.
+func withLock(path string, function func() error) error {
+	lockPath := path + ".lock"
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		lockFile, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o644)
+		if err == nil {
+			lockFile.Close()
+			break
+		}
+		if !os.IsExist(err) {
+			return err
+		}
+
+		if info, statErr := os.Stat(lockPath); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(lockPath)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("timed out waiting for lock %s", lockPath)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+	defer os.Remove(lockPath)
+
+	return function()
+}
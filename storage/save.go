@@ -0,0 +1,64 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+// AutosavePath returns the path of the save file that is written when
+// quitting and offered for resuming on the next launch.
+func AutosavePath() (string, error) {
+	dataDir, err := DataDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dataDir, "autosave.json"), nil
+}
+
+// SaveSession writes the complete session to the given file.
+func SaveSession(path string, session *state.GameSession) error {
+	data, err := json.MarshalIndent(session.Save(), "", "\t")
+	if err != nil {
+		return err
+	}
+
+	if err := WriteFileAtomic(path, data); err != nil {
+		return fmt.Errorf("error writing save file %s: %w", path, err)
+	}
+	return nil
+}
+
+// LoadSession reads a session previously written by SaveSession.
+func LoadSession(path string, renderNotificationChannel chan bool) (*state.GameSession, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading save file: %w", err)
+	}
+
+	//The version is checked first, as newer formats might not even be
+	//decodable into the current structure.
+	var header struct {
+		Version int `json:"version"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return nil, fmt.Errorf("save file %s is corrupted: %w", path, err)
+	}
+	if header.Version != state.SaveVersion {
+		return nil, fmt.Errorf("save file %s has format version %d, but only version %d is supported",
+			path, header.Version, state.SaveVersion)
+	}
+
+	var save state.SaveGame
+	if err := json.Unmarshal(data, &save); err != nil {
+		return nil, fmt.Errorf("save file %s is corrupted: %w", path, err)
+	}
+	session, err := state.LoadGameSession(renderNotificationChannel, save)
+	if err != nil {
+		return nil, fmt.Errorf("save file %s is corrupted: %w", path, err)
+	}
+	return session, nil
+}
@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Bios-Marcel/2048-terminal/state"
+)
+
+func TestSaveAndLoadSession(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "slots", "slot.json")
+	session, _ := state.NewGameSession(nil, state.DefaultRules(), 5)
+	session.Left()
+	session.Up()
+
+	if err := SaveSession(path, session); err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	loaded, err := LoadSession(path, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if loaded.Score() != session.Score() || loaded.MoveCount() != session.MoveCount() {
+		t.Errorf("Loaded session differs from saved session")
+	}
+}
+
+func TestLoadSession_Errors(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       string
+		expectedError string
+	}{
+		{
+			name:          "corrupted",
+			content:       fmt.Sprintf(`{"version": %d, "rules": `, state.SaveVersion),
+			expectedError: "corrupted",
+		},
+		{
+			name:          "incompatible",
+			content:       `{"version": 1000, "something": "else"}`,
+			expectedError: "version 1000",
+		},
+		{
+			name:          "inconsistent",
+			content:       fmt.Sprintf(`{"version": %d, "rules": {"width": 4, "height": 4}}`, state.SaveVersion),
+			expectedError: "corrupted",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "save.json")
+			if err := os.WriteFile(path, []byte(test.content), 0o644); err != nil {
+				t.Fatalf("Unexpected error: %s", err)
+			}
+
+			_, err := LoadSession(path, nil)
+			if err == nil || !strings.Contains(err.Error(), test.expectedError) {
+				t.Errorf("Expected error containing %q, got: %v", test.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDataDir(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", "/tmp/data")
+
+	dataDir, err := DataDir()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if dataDir != "/tmp/data/2048-terminal" {
+		t.Errorf("Expected data dir inside of XDG_DATA_HOME, got %s", dataDir)
+	}
+}
@@ -0,0 +1,56 @@
+// Package storage persists data of the game, such as saved sessions, to
+// disk.
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+const appDirectory = "2048-terminal"
+
+// DataDir returns the directory all data is stored in, following the XDG
+// base directory specification. The directory isn't created.
+func DataDir() (string, error) {
+	if dataHome := os.Getenv("XDG_DATA_HOME"); filepath.IsAbs(dataHome) {
+		return filepath.Join(dataHome, appDirectory), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", errors.New("can't determine data directory, neither XDG_DATA_HOME nor HOME are set")
+	}
+	return filepath.Join(home, ".local", "share", appDirectory), nil
+}
+
+// WriteFileAtomic writes the data to a temporary file in the same
+// directory and then replaces the target file with it. This way readers
+// never see partially written files. Missing directories are created.
+func WriteFileAtomic(path string, data []byte) error {
+	directory := filepath.Dir(path)
+	if err := os.MkdirAll(directory, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(directory, "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	//Only has an effect if anything goes wrong before renaming.
+	defer os.Remove(file.Name())
+
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
@@ -1,6 +1,10 @@
 package main
 
-import "github.com/gdamore/tcell/v2"
+import (
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
 
 // createScreen generates a ready to use screen. The screen has
 // no cursor and doesn't support mouse eventing.
@@ -23,8 +27,37 @@ func createScreen() (tcell.Screen, error) {
 	return screen, nil
 }
 
+// eventIsRune checks whether the given rune has been typed, ignoring its
+// case. Shift is allowed, as some terminals report it for upper case
+// letters and symbols such as '+'.
 func eventIsRune(event *tcell.EventKey, r rune) bool {
-	return event.Key() == tcell.KeyRune && event.Rune() == r && event.Modifiers() == 0
+	return event.Key() == tcell.KeyRune &&
+		unicode.ToLower(event.Rune()) == unicode.ToLower(r) &&
+		event.Modifiers()&^tcell.ModShift == 0
+}
+
+// askYesNo shows the question and blocks until it has been answered with
+// either Y or N. Escape and Ctrl+C count as N.
+func askYesNo(screen tcell.Screen, question string) bool {
+	screen.Clear()
+	drawText(screen, 0, 0, tcell.StyleDefault, question)
+	screen.Show()
+	defer screen.Clear()
+
+	for {
+		event, isKeyEvent := screen.PollEvent().(*tcell.EventKey)
+		if !isKeyEvent {
+			continue
+		}
+
+		if eventIsRune(event, 'y') || event.Key() == tcell.KeyEnter {
+			return true
+		}
+		if eventIsRune(event, 'n') ||
+			event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyCtrlC {
+			return false
+		}
+	}
 }
 
 func drawRectangle(screen tcell.Screen, xStart, yStart, width, height int, style tcell.Style) {
@@ -34,3 +67,30 @@ func drawRectangle(screen tcell.Screen, xStart, yStart, width, height int, style
 		}
 	}
 }
+
+// drawBorder draws a box-drawing border along the edges of the given
+// rectangle.
+func drawBorder(screen tcell.Screen, xStart, yStart, width, height int, style tcell.Style) {
+	xEnd := xStart + width - 1
+	yEnd := yStart + height - 1
+	for x := xStart + 1; x < xEnd; x++ {
+		screen.SetContent(x, yStart, tcell.RuneHLine, nil, style)
+		screen.SetContent(x, yEnd, tcell.RuneHLine, nil, style)
+	}
+	for y := yStart + 1; y < yEnd; y++ {
+		screen.SetContent(xStart, y, tcell.RuneVLine, nil, style)
+		screen.SetContent(xEnd, y, tcell.RuneVLine, nil, style)
+	}
+	screen.SetContent(xStart, yStart, tcell.RuneULCorner, nil, style)
+	screen.SetContent(xEnd, yStart, tcell.RuneURCorner, nil, style)
+	screen.SetContent(xStart, yEnd, tcell.RuneLLCorner, nil, style)
+	screen.SetContent(xEnd, yEnd, tcell.RuneLRCorner, nil, style)
+}
+
+// drawText draws a single line of text, starting at the given position.
+func drawText(screen tcell.Screen, x, y int, style tcell.Style, text string) {
+	for _, r := range text {
+		screen.SetContent(x, y, r, nil, style)
+		x++
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestEventIsRune(t *testing.T) {
+	testCases := []struct {
+		event    *tcell.EventKey
+		expected bool
+	}{
+		{tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModNone), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'U', tcell.ModNone), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'U', tcell.ModShift), true},
+		{tcell.NewEventKey(tcell.KeyRune, 'u', tcell.ModAlt), false},
+		{tcell.NewEventKey(tcell.KeyRune, 'r', tcell.ModNone), false},
+		{tcell.NewEventKey(tcell.KeyCtrlU, 0, tcell.ModCtrl), false},
+	}
+	for _, testCase := range testCases {
+		if actual := eventIsRune(testCase.event, 'u'); actual != testCase.expected {
+			t.Errorf("Expected %t for %s, got %t", testCase.expected, testCase.event.Name(), actual)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// DefaultName is the name of the theme used if none has been chosen.
+const DefaultName = "classic"
+
+// tileColors are the background and foreground color of a tile.
+type tileColors struct {
+	background tcell.Color
+	foreground tcell.Color
+}
+
+func newTiles(colors map[uint]tileColors) map[uint]tcell.Style {
+	tiles := make(map[uint]tcell.Style, len(colors))
+	for value, tile := range colors {
+		tiles[value] = tcell.StyleDefault.Background(tile.background).Foreground(tile.foreground).Bold(true)
+	}
+	return tiles
+}
+
+func solid(color tcell.Color) tcell.Style {
+	return tcell.StyleDefault.Background(color).Foreground(color)
+}
+
+var (
+	//The colors of the original game.
+	classicDark  = tcell.NewHexColor(0x776e65)
+	classicLight = tcell.NewHexColor(0xf9f6f2)
+	classicTiles = map[uint]tileColors{
+		2:    {tcell.NewHexColor(0xeee4da), classicDark},
+		4:    {tcell.NewHexColor(0xede0c8), classicDark},
+		8:    {tcell.NewHexColor(0xf2b179), classicLight},
+		16:   {tcell.NewHexColor(0xf59563), classicLight},
+		32:   {tcell.NewHexColor(0xf67c5f), classicLight},
+		64:   {tcell.NewHexColor(0xf65e3b), classicLight},
+		128:  {tcell.NewHexColor(0xedcf72), classicLight},
+		256:  {tcell.NewHexColor(0xedcc61), classicLight},
+		512:  {tcell.NewHexColor(0xedc850), classicLight},
+		1024: {tcell.NewHexColor(0xedc53f), classicLight},
+		2048: {tcell.NewHexColor(0xedc22e), classicLight},
+		4096: {tcell.NewHexColor(0x3c3a32), classicLight},
+	}
+	classicEmpty  = tcell.NewHexColor(0xcdc1b4)
+	classicGap    = tcell.NewHexColor(0xbbada0)
+	classicButton = tcell.NewHexColor(0x8f7a66)
+)
+
+// Bundled returns all themes shipped with the game, starting with the
+// default theme. They are created anew on each call, so that they can't
+// be modified by accident.
+func Bundled() []*Theme {
+	return []*Theme{
+		{
+			Name:       "classic",
+			Tiles:      newTiles(classicTiles),
+			Empty:      solid(classicEmpty),
+			Background: tcell.StyleDefault,
+			Gap:        solid(classicGap),
+			Overlay:    tcell.StyleDefault.Background(classicButton).Foreground(classicLight).Bold(true),
+			Panel:      tcell.StyleDefault,
+			Hint:       tcell.StyleDefault.Background(classicGap).Foreground(classicLight).Bold(true),
+		},
+		{
+			Name:       "light",
+			Tiles:      newTiles(classicTiles),
+			Empty:      solid(classicEmpty),
+			Background: tcell.StyleDefault.Background(tcell.NewHexColor(0xfaf8ef)).Foreground(classicDark),
+			Gap:        solid(classicGap),
+			Overlay:    tcell.StyleDefault.Background(classicButton).Foreground(classicLight).Bold(true),
+			Panel:      tcell.StyleDefault.Background(tcell.NewHexColor(0xfaf8ef)).Foreground(classicDark).Bold(true),
+			Hint:       tcell.StyleDefault.Background(classicGap).Foreground(classicLight).Bold(true),
+		},
+		{
+			Name: "dark",
+			Tiles: newTiles(map[uint]tileColors{
+				2:    {tcell.NewHexColor(0x3a3f4b), tcell.NewHexColor(0xd7dae0)},
+				4:    {tcell.NewHexColor(0x3e4a5c), tcell.NewHexColor(0xd7dae0)},
+				8:    {tcell.NewHexColor(0xb5651d), tcell.NewHexColor(0xffffff)},
+				16:   {tcell.NewHexColor(0xc0502e), tcell.NewHexColor(0xffffff)},
+				32:   {tcell.NewHexColor(0xc7403a), tcell.NewHexColor(0xffffff)},
+				64:   {tcell.NewHexColor(0xd62828), tcell.NewHexColor(0xffffff)},
+				128:  {tcell.NewHexColor(0xc9a227), tcell.NewHexColor(0x1e1e1e)},
+				256:  {tcell.NewHexColor(0xd4af37), tcell.NewHexColor(0x1e1e1e)},
+				512:  {tcell.NewHexColor(0xe0b53d), tcell.NewHexColor(0x1e1e1e)},
+				1024: {tcell.NewHexColor(0xe9c46a), tcell.NewHexColor(0x1e1e1e)},
+				2048: {tcell.NewHexColor(0xf4d35e), tcell.NewHexColor(0x1e1e1e)},
+				4096: {tcell.NewHexColor(0x6a4c93), tcell.NewHexColor(0xffffff)},
+				8192: {tcell.NewHexColor(0x4361ee), tcell.NewHexColor(0xffffff)},
+			}),
+			Empty:      solid(tcell.NewHexColor(0x2d2d2d)),
+			Background: tcell.StyleDefault.Background(tcell.NewHexColor(0x1e1e1e)).Foreground(tcell.NewHexColor(0xd4d4d4)),
+			Gap:        solid(tcell.NewHexColor(0x121212)),
+			Overlay:    tcell.StyleDefault.Background(tcell.NewHexColor(0x3c3c3c)).Foreground(tcell.NewHexColor(0xffffff)).Bold(true),
+			Panel:      tcell.StyleDefault.Background(tcell.NewHexColor(0x1e1e1e)).Foreground(tcell.NewHexColor(0xd4d4d4)),
+			Hint:       tcell.StyleDefault.Background(tcell.NewHexColor(0x121212)).Foreground(tcell.NewHexColor(0xf4d35e)).Bold(true),
+		},
+		{
+			//The classic colors, approximated with the 256 color palette.
+			Name: "256",
+			Tiles: newTiles(map[uint]tileColors{
+				2:    {tcell.PaletteColor(255), tcell.PaletteColor(241)},
+				4:    {tcell.PaletteColor(223), tcell.PaletteColor(241)},
+				8:    {tcell.PaletteColor(215), tcell.PaletteColor(231)},
+				16:   {tcell.PaletteColor(209), tcell.PaletteColor(231)},
+				32:   {tcell.PaletteColor(203), tcell.PaletteColor(231)},
+				64:   {tcell.PaletteColor(196), tcell.PaletteColor(231)},
+				128:  {tcell.PaletteColor(222), tcell.PaletteColor(231)},
+				256:  {tcell.PaletteColor(221), tcell.PaletteColor(231)},
+				512:  {tcell.PaletteColor(220), tcell.PaletteColor(231)},
+				1024: {tcell.PaletteColor(214), tcell.PaletteColor(231)},
+				2048: {tcell.PaletteColor(178), tcell.PaletteColor(231)},
+				4096: {tcell.PaletteColor(237), tcell.PaletteColor(231)},
+			}),
+			Empty:      solid(tcell.PaletteColor(250)),
+			Background: tcell.StyleDefault,
+			Gap:        solid(tcell.PaletteColor(248)),
+			Overlay:    tcell.StyleDefault.Background(tcell.PaletteColor(95)).Foreground(tcell.PaletteColor(231)).Bold(true),
+			Panel:      tcell.StyleDefault,
+			Hint:       tcell.StyleDefault.Background(tcell.PaletteColor(248)).Foreground(tcell.PaletteColor(231)).Bold(true),
+		},
+		{
+			//Only the 16 basic colors, which most terminals support.
+			Name: "16",
+			Tiles: newTiles(map[uint]tileColors{
+				2:     {tcell.ColorSilver, tcell.ColorBlack},
+				4:     {tcell.ColorWhite, tcell.ColorBlack},
+				8:     {tcell.ColorOlive, tcell.ColorWhite},
+				16:    {tcell.ColorMaroon, tcell.ColorWhite},
+				32:    {tcell.ColorRed, tcell.ColorWhite},
+				64:    {tcell.ColorPurple, tcell.ColorWhite},
+				128:   {tcell.ColorYellow, tcell.ColorBlack},
+				256:   {tcell.ColorGreen, tcell.ColorWhite},
+				512:   {tcell.ColorLime, tcell.ColorBlack},
+				1024:  {tcell.ColorTeal, tcell.ColorWhite},
+				2048:  {tcell.ColorAqua, tcell.ColorBlack},
+				4096:  {tcell.ColorNavy, tcell.ColorWhite},
+				8192:  {tcell.ColorBlue, tcell.ColorWhite},
+				16384: {tcell.ColorFuchsia, tcell.ColorBlack},
+			}),
+			Empty:      solid(tcell.ColorGray),
+			Background: tcell.StyleDefault,
+			Gap:        solid(tcell.ColorBlack),
+			Overlay:    tcell.StyleDefault.Background(tcell.ColorNavy).Foreground(tcell.ColorWhite).Bold(true),
+			Panel:      tcell.StyleDefault,
+			Hint:       tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite).Bold(true),
+		},
+		{
+			//Only the 8 colors of the most basic terminals, without the
+			//bright variants. Later tiles repeat the backgrounds with
+			//different foregrounds.
+			Name: "8",
+			Tiles: newTiles(map[uint]tileColors{
+				2:    {tcell.ColorSilver, tcell.ColorBlack},
+				4:    {tcell.ColorTeal, tcell.ColorBlack},
+				8:    {tcell.ColorOlive, tcell.ColorBlack},
+				16:   {tcell.ColorGreen, tcell.ColorBlack},
+				32:   {tcell.ColorMaroon, tcell.ColorSilver},
+				64:   {tcell.ColorPurple, tcell.ColorSilver},
+				128:  {tcell.ColorSilver, tcell.ColorMaroon},
+				256:  {tcell.ColorTeal, tcell.ColorMaroon},
+				512:  {tcell.ColorOlive, tcell.ColorNavy},
+				1024: {tcell.ColorGreen, tcell.ColorNavy},
+				2048: {tcell.ColorMaroon, tcell.ColorOlive},
+				4096: {tcell.ColorSilver, tcell.ColorPurple},
+			}),
+			Empty:      solid(tcell.ColorBlack),
+			Background: tcell.StyleDefault,
+			Gap:        solid(tcell.ColorNavy),
+			Overlay:    tcell.StyleDefault.Background(tcell.ColorSilver).Foreground(tcell.ColorBlack).Bold(true),
+			Panel:      tcell.StyleDefault,
+			Hint:       tcell.StyleDefault.Background(tcell.ColorNavy).Foreground(tcell.ColorSilver).Bold(true),
+		},
+		{
+			//Bright tiles on a black board, with neighbouring values
+			//sharing a color and large values standing out.
+			Name: "high-contrast",
+			Tiles: newTiles(map[uint]tileColors{
+				2:    {tcell.ColorWhite, tcell.ColorBlack},
+				8:    {tcell.ColorYellow, tcell.ColorBlack},
+				32:   {tcell.ColorAqua, tcell.ColorBlack},
+				128:  {tcell.ColorLime, tcell.ColorBlack},
+				512:  {tcell.ColorFuchsia, tcell.ColorBlack},
+				2048: {tcell.ColorRed, tcell.ColorWhite},
+				4096: {tcell.ColorBlue, tcell.ColorWhite},
+			}),
+			Empty:      solid(tcell.ColorBlack),
+			Background: tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite),
+			Gap:        solid(tcell.ColorGray),
+			Overlay:    tcell.StyleDefault.Background(tcell.ColorWhite).Foreground(tcell.ColorBlack).Bold(true),
+			Panel:      tcell.StyleDefault.Background(tcell.ColorBlack).Foreground(tcell.ColorWhite).Bold(true),
+			Hint:       tcell.StyleDefault.Background(tcell.ColorGray).Foreground(tcell.ColorYellow).Bold(true),
+		},
+		{
+			//No colors at all, for monochrome terminals and NO_COLOR.
+			//Tiles have borders, or brackets in the compact layout, and
+			//differ by their attributes.
+			Name:       "mono",
+			Tiles:      monoTiles(),
+			Empty:      tcell.StyleDefault.Dim(true),
+			Background: tcell.StyleDefault,
+			Gap:        tcell.StyleDefault,
+			Overlay:    tcell.StyleDefault.Reverse(true),
+			Panel:      tcell.StyleDefault,
+			Hint:       tcell.StyleDefault.Bold(true),
+			Borders:    true,
+		},
+	}
+}
+
+// monoTiles gives each tile up to 2048 its own combination of attributes.
+// Dim is never used alone, as that's the style of empty cells, and never
+// combined with bold, which many terminals can't show at once. Tiles from
+// 4096 upwards repeat the combinations in italics.
+func monoTiles() map[uint]tcell.Style {
+	plain := tcell.StyleDefault
+	combinations := []tcell.Style{
+		plain,
+		plain.Underline(true),
+		plain.Bold(true),
+		plain.Bold(true).Underline(true),
+		plain.Reverse(true),
+		plain.Reverse(true).Underline(true),
+		plain.Reverse(true).Bold(true),
+		plain.Reverse(true).Bold(true).Underline(true),
+		plain.Dim(true).Underline(true),
+		plain.Dim(true).Reverse(true),
+		plain.Dim(true).Reverse(true).Underline(true),
+	}
+
+	tiles := make(map[uint]tcell.Style)
+	for index := 0; index < 2*len(combinations); index++ {
+		tiles[1<<(index+1)] = combinations[index%len(combinations)].Italic(index >= len(combinations))
+	}
+	return tiles
+}
+
+// Find returns the bundled theme with the given name.
+func Find(name string) (*Theme, bool) {
+	for _, theme := range Bundled() {
+		if theme.Name == name {
+			return theme, true
+		}
+	}
+	return nil, false
+}
+
+// Names returns the names of all bundled themes.
+func Names() []string {
+	themes := Bundled()
+	names := make([]string, 0, len(themes))
+	for _, theme := range themes {
+		names = append(names, theme.Name)
+	}
+	return names
+}
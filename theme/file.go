@@ -0,0 +1,173 @@
+package theme
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// themeFile is the JSON representation of a theme. Missing styles are
+// taken from the base theme.
+type themeFile struct {
+	Name       string                `json:"name"`
+	Base       string                `json:"base"`
+	Tiles      map[string]*styleFile `json:"tiles"`
+	Empty      *styleFile            `json:"empty"`
+	Background *styleFile            `json:"background"`
+	Gap        *styleFile            `json:"gap"`
+	Overlay    *styleFile            `json:"overlay"`
+	Panel      *styleFile            `json:"panel"`
+	Hint       *styleFile            `json:"hint"`
+	Borders    *bool                 `json:"borders"`
+}
+
+type styleFile struct {
+	Foreground string `json:"foreground"`
+	Background string `json:"background"`
+	Bold       bool   `json:"bold"`
+	Underline  bool   `json:"underline"`
+	Reverse    bool   `json:"reverse"`
+}
+
+// Read parses a JSON encoded theme such as:
+//
+//	{
+//		"name": "ocean",
+//		"base": "dark",
+//		"tiles": {
+//			"2": {"foreground": "#ffffff", "background": "#0077be", "bold": true},
+//			"4": {"foreground": "white", "background": "27"}
+//		},
+//		"empty": {"background": "navy", "foreground": "navy"}
+//	}
+//
+// Colors are given as "#rrggbb", W3C color names, indexes into the 256
+// color palette or "default" for the terminal's default color. All fields
+// are optional. Missing styles are taken from the bundled theme named by
+// "base", which defaults to the default theme. If "tiles" is given, it
+// replaces all tiles of the base theme. The other styles are "background",
+// "gap", "overlay", "panel" and "hint", see Theme. "borders" can be set to
+// true or false to turn borders around tiles on or off.
+func Read(reader io.Reader) (*Theme, error) {
+	var file themeFile
+	decoder := json.NewDecoder(reader)
+	//Typos would otherwise silently be ignored.
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&file); err != nil {
+		return nil, fmt.Errorf("invalid theme: %w", err)
+	}
+
+	baseName := file.Base
+	if baseName == "" {
+		baseName = DefaultName
+	}
+	base, found := Find(baseName)
+	if !found {
+		return nil, fmt.Errorf("unknown base theme %q, expected one of: %s", baseName, strings.Join(Names(), ", "))
+	}
+	theme := base.Copy()
+	theme.Name = file.Name
+
+	if file.Tiles != nil {
+		theme.Tiles = make(map[uint]tcell.Style, len(file.Tiles))
+		for key, tile := range file.Tiles {
+			value, err := strconv.ParseUint(key, 10, 0)
+			if err != nil || value == 0 {
+				return nil, fmt.Errorf("invalid tile value %q", key)
+			}
+			style, err := tile.style()
+			if err != nil {
+				return nil, fmt.Errorf("invalid style for tile %d: %w", value, err)
+			}
+			theme.Tiles[uint(value)] = style
+		}
+	}
+
+	styles := []struct {
+		name   string
+		file   *styleFile
+		target *tcell.Style
+	}{
+		{"empty", file.Empty, &theme.Empty},
+		{"background", file.Background, &theme.Background},
+		{"gap", file.Gap, &theme.Gap},
+		{"overlay", file.Overlay, &theme.Overlay},
+		{"panel", file.Panel, &theme.Panel},
+		{"hint", file.Hint, &theme.Hint},
+	}
+	for _, style := range styles {
+		if style.file == nil {
+			continue
+		}
+		parsed, err := style.file.style()
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s style: %w", style.name, err)
+		}
+		*style.target = parsed
+	}
+	if file.Borders != nil {
+		theme.Borders = *file.Borders
+	}
+	return theme, nil
+}
+
+// ReadFile reads a theme from the given JSON file, see Read. Themes
+// without a name are named after the file.
+func ReadFile(path string) (*Theme, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	theme, err := Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if theme.Name == "" {
+		theme.Name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return theme, nil
+}
+
+func (file *styleFile) style() (tcell.Style, error) {
+	foreground, err := parseColor(file.Foreground)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	background, err := parseColor(file.Background)
+	if err != nil {
+		return tcell.StyleDefault, err
+	}
+	return tcell.StyleDefault.
+		Foreground(foreground).
+		Background(background).
+		Bold(file.Bold).
+		Underline(file.Underline).
+		Reverse(file.Reverse), nil
+}
+
+func parseColor(value string) (tcell.Color, error) {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if value == "" || value == "default" {
+		return tcell.ColorDefault, nil
+	}
+	if index, err := strconv.Atoi(value); err == nil {
+		if index < 0 || index > 255 {
+			return tcell.ColorDefault, fmt.Errorf("palette index %d isn't between 0 and 255", index)
+		}
+		return tcell.PaletteColor(index), nil
+	}
+
+	color := tcell.GetColor(value)
+	if color == tcell.ColorDefault {
+		return tcell.ColorDefault, fmt.Errorf("unknown color %q", value)
+	}
+	return color, nil
+}
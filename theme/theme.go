@@ -0,0 +1,67 @@
+// Package theme defines the colors the game is drawn with. There are a
+// couple of bundled themes, further themes can be loaded from JSON files.
+package theme
+
+import (
+	"github.com/gdamore/tcell/v2"
+)
+
+// Theme is a set of styles for all parts of the game.
+type Theme struct {
+	Name string
+	// Tiles maps tile values to their style. Values without a style of
+	// their own use the style of the next lower value that has one, or of
+	// the lowest value, if there's none.
+	Tiles map[uint]tcell.Style
+	// Empty is the style of cells without a tile.
+	Empty tcell.Style
+	// Background is the style of the screen behind everything else.
+	Background tcell.Style
+	// Gap is the style of the grid between the cells.
+	Gap tcell.Style
+	// Overlay is the style of messages drawn on top of the board.
+	Overlay tcell.Style
+	// Panel is the style of the panel and the status lines.
+	Panel tcell.Style
+	// Hint is the style of the arrows drawn into the gaps for hints.
+	Hint tcell.Style
+	// Borders draws box-drawing borders around tiles, so that they can
+	// be told apart from empty cells without colors.
+	Borders bool
+}
+
+// Tile returns the style for a cell with the given value, with 0 being an
+// empty cell.
+func (theme *Theme) Tile(value uint) tcell.Style {
+	if value == 0 || len(theme.Tiles) == 0 {
+		return theme.Empty
+	}
+	if style, found := theme.Tiles[value]; found {
+		return style
+	}
+
+	var lower, lowest uint
+	for tileValue := range theme.Tiles {
+		if tileValue < value && tileValue > lower {
+			lower = tileValue
+		}
+		if lowest == 0 || tileValue < lowest {
+			lowest = tileValue
+		}
+	}
+	if lower == 0 {
+		return theme.Tiles[lowest]
+	}
+	return theme.Tiles[lower]
+}
+
+// Copy returns a deep copy of the theme, which can be modified without
+// affecting the original.
+func (theme *Theme) Copy() *Theme {
+	copied := *theme
+	copied.Tiles = make(map[uint]tcell.Style, len(theme.Tiles))
+	for value, style := range theme.Tiles {
+		copied.Tiles[value] = style
+	}
+	return &copied
+}
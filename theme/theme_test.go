@@ -0,0 +1,171 @@
+package theme
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestTheme_Tile(t *testing.T) {
+	theme := &Theme{
+		Tiles: map[uint]tcell.Style{
+			4:  tcell.StyleDefault.Background(tcell.ColorRed),
+			16: tcell.StyleDefault.Background(tcell.ColorBlue),
+		},
+		Empty: tcell.StyleDefault.Background(tcell.ColorGray),
+	}
+
+	testCases := []struct {
+		value    uint
+		expected tcell.Color
+	}{
+		{0, tcell.ColorGray},
+		{2, tcell.ColorRed},
+		{4, tcell.ColorRed},
+		{8, tcell.ColorRed},
+		{16, tcell.ColorBlue},
+		{1 << 20, tcell.ColorBlue},
+	}
+	for _, testCase := range testCases {
+		_, background, _ := theme.Tile(testCase.value).Decompose()
+		if background != testCase.expected {
+			t.Errorf("Expected background %v for %d, got %v", testCase.expected, testCase.value, background)
+		}
+	}
+}
+
+func TestBundled(t *testing.T) {
+	themes := Bundled()
+	if themes[0].Name != DefaultName {
+		t.Errorf("Expected default theme first, got %s", themes[0].Name)
+	}
+
+	for _, theme := range themes {
+		//Each tile up to 2048 has to be told apart from an empty cell and
+		//its predecessor, unless the theme groups tiles on purpose.
+		for value := uint(2); value <= 2048; value *= 2 {
+			if theme.Tile(value) == theme.Empty {
+				t.Errorf("Tile %d of theme %s looks like an empty cell", value, theme.Name)
+			}
+		}
+		if theme.Name != "high-contrast" && theme.Tile(512) == theme.Tile(256) {
+			t.Errorf("Tile 512 of theme %s looks like 256", theme.Name)
+		}
+		if theme.Tile(1<<16) == theme.Empty {
+			t.Errorf("Large tiles of theme %s look like empty cells", theme.Name)
+		}
+	}
+
+	//Modifying a theme mustn't affect the bundled themes.
+	themes[0].Tiles[2] = tcell.StyleDefault
+	if theme, _ := Find(DefaultName); theme.Tiles[2] == tcell.StyleDefault {
+		t.Error("Bundled theme has been modified")
+	}
+}
+
+func TestRead(t *testing.T) {
+	theme, err := Read(strings.NewReader(`{
+		"name": "ocean",
+		"base": "dark",
+		"tiles": {
+			"2": {"foreground": "#FFFFFF", "background": "navy", "bold": true},
+			"8": {"background": "27"}
+		},
+		"gap": {"background": "default", "reverse": true},
+		"borders": true
+	}`))
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if theme.Name != "ocean" {
+		t.Errorf("Expected name ocean, got %s", theme.Name)
+	}
+
+	expectedTwo := tcell.StyleDefault.Foreground(tcell.NewHexColor(0xffffff)).Background(tcell.ColorNavy).Bold(true)
+	if theme.Tile(2) != expectedTwo || theme.Tile(4) != expectedTwo {
+		t.Errorf("Unexpected style for tiles 2 and 4: %v", theme.Tile(2))
+	}
+	if _, background, _ := theme.Tile(2048).Decompose(); background != tcell.PaletteColor(27) {
+		t.Errorf("Expected tiles of base theme to be replaced, got background %v", background)
+	}
+	if theme.Gap != tcell.StyleDefault.Reverse(true) {
+		t.Errorf("Unexpected gap style: %v", theme.Gap)
+	}
+	if !theme.Borders {
+		t.Error("Expected borders to be turned on")
+	}
+
+	dark, _ := Find("dark")
+	if theme.Empty != dark.Empty || theme.Overlay != dark.Overlay {
+		t.Error("Expected missing styles to be taken from the base theme")
+	}
+}
+
+func TestRead_Errors(t *testing.T) {
+	testCases := map[string]string{
+		"syntax":        `{"name": `,
+		"unknown field": `{"colour": {}}`,
+		"unknown base":  `{"base": "neon"}`,
+		"tile value":    `{"tiles": {"two": {}}}`,
+		"zero tile":     `{"tiles": {"0": {}}}`,
+		"color":         `{"panel": {"foreground": "blurple"}}`,
+		"palette index": `{"tiles": {"2": {"background": "256"}}}`,
+	}
+	for name, input := range testCases {
+		if _, err := Read(strings.NewReader(input)); err == nil {
+			t.Errorf("Expected error for %s", name)
+		}
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mine.json")
+	if err := os.WriteFile(path, []byte(`{"base": "light"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	theme, err := ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+	if theme.Name != "mine" {
+		t.Errorf("Expected theme to be named after the file, got %q", theme.Name)
+	}
+}
+
+func TestMono(t *testing.T) {
+	mono, found := Find("mono")
+	if !found {
+		t.Fatal("Expected mono theme")
+	}
+
+	styles := []tcell.Style{mono.Empty, mono.Background, mono.Gap, mono.Overlay, mono.Panel, mono.Hint}
+	for _, style := range mono.Tiles {
+		styles = append(styles, style)
+	}
+	for _, style := range styles {
+		if foreground, background, _ := style.Decompose(); foreground != tcell.ColorDefault || background != tcell.ColorDefault {
+			t.Errorf("Expected mono theme to use no colors, got %v on %v", foreground, background)
+		}
+	}
+	seen := make(map[tcell.Style]uint)
+	for value := uint(2); value <= 1<<16; value *= 2 {
+		if other, found := seen[mono.Tile(value)]; found {
+			t.Errorf("Tiles %d and %d look the same", other, value)
+		}
+		seen[mono.Tile(value)] = value
+	}
+
+	//Without italics, at least the tiles up to 2048 have to differ.
+	seen = make(map[tcell.Style]uint)
+	for value := uint(2); value <= 2048; value *= 2 {
+		style := mono.Tile(value).Italic(false)
+		if other, found := seen[style]; found {
+			t.Errorf("Tiles %d and %d look the same without italics", other, value)
+		}
+		seen[style] = value
+	}
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"github.com/Bios-Marcel/2048-terminal/theme"
+)
+
+// loadThemes returns the themes that can be switched between and the index
+// of the chosen one. The choice is either the name of a bundled theme or
+// the path of a theme file, which is added to the bundled themes. Without
+// a choice, the bundled theme fitting the terminal's colors is chosen.
+func loadThemes(choice string, support colorSupport) ([]*theme.Theme, int, error) {
+	if choice == "" {
+		choice = themeFor(support)
+	}
+	themes := theme.Bundled()
+	for index, bundled := range themes {
+		if bundled.Name == choice {
+			return themes, index, nil
+		}
+	}
+
+	custom, err := theme.ReadFile(choice)
+	if err != nil {
+		return nil, 0, err
+	}
+	return append(themes, custom), len(themes), nil
+}